@@ -0,0 +1,33 @@
+// Package assets supplies the images the game draws with. The game loads
+// them from the files in this directory, while headless runs, such as tests
+// and batch simulations, use Null and never touch the disk or need a
+// graphics context.
+package assets
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Provider loads images by path, relative to the working directory.
+type Provider interface {
+	Image(path string) (*ebiten.Image, error)
+}
+
+// Files loads images from disk.
+type Files struct{}
+
+// Image reads the image file at path.
+func (Files) Image(path string) (*ebiten.Image, error) {
+	img, _, err := ebitenutil.NewImageFromFile(path)
+	return img, err
+}
+
+// Null loads nothing. Every image is nil, so a game using it can be
+// simulated but not drawn.
+type Null struct{}
+
+// Image returns a nil image.
+func (Null) Image(path string) (*ebiten.Image, error) {
+	return nil, nil
+}
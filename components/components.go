@@ -1,6 +1,7 @@
 package components
 
 import (
+	"github.com/bytearena/ecs"
 	"github.com/hajimehoshi/ebiten/v2"
 	"math"
 )
@@ -18,17 +19,136 @@ func (p *Position) GetManhattanDistance(other *Position) int {
 	return int(xDist) + int(yDist)
 }
 
+// GetChebyshevDistance is the number of moves between two positions when
+// diagonal moves are allowed.
+func (p *Position) GetChebyshevDistance(other *Position) int {
+	xDist := math.Abs(float64(p.X - other.X))
+	yDist := math.Abs(float64(p.Y - other.Y))
+	return int(math.Max(xDist, yDist))
+}
+
 func (p *Position) IsEqual(other *Position) bool {
 	return (p.X == other.X && p.Y == other.Y)
 }
 
+// Renderable is how an entity is drawn. Sprite is the path the image was
+// loaded from, so the image can be loaded again when a game is resumed.
 type Renderable struct {
-	Image *ebiten.Image
+	Image  *ebiten.Image
+	Sprite string
 }
 
 type Movable struct{}
 
-type Monster struct{}
+// Monster marks an entity as a monster. Template is the ID of the monster
+// template it was made from.
+type Monster struct {
+	Template string
+}
+
+// Experience is how far an entity has come: the XP it has earned, the
+// level that has brought it to, and the bonus to hit its levels have given
+// it on top of its weapon's.
+type Experience struct {
+	Level      int
+	XP         int
+	ToHitBonus int
+}
+
+// Corpse is what is left where a monster died. It lies on the floor and
+// can't be picked up.
+type Corpse struct{}
+
+// Gold is a pile of gold pieces lying on the floor. It is picked up by
+// walking over it.
+type Gold struct {
+	Amount int
+}
+
+// Depth records which dungeon level an entity lives on. Entities on levels
+// other than the current one are kept but not simulated.
+type Depth struct {
+	Level int
+}
+
+// Key is an item that unlocks one locked door. It is picked up by walking
+// over it.
+type Key struct{}
+
+// Keyring counts the keys an entity is carrying.
+type Keyring struct {
+	Keys int
+}
+
+// Slot is where an item is held or worn. An entity can have one item
+// equipped in each slot, except rings, which go on either hand.
+type Slot string
+
+const (
+	SlotWeapon Slot = "weapon"
+	SlotBody   Slot = "body"
+	SlotHead   Slot = "head"
+	SlotShield Slot = "shield"
+	SlotRing   Slot = "ring"
+)
+
+// RingSlots is how many rings an entity can wear at once.
+const RingSlots = 2
+
+// Item is something that can lie on the floor and be carried. Template is
+// the ID of the template it was made from. While equipped, a weapon attacks
+// with its Damage and ToHitBonus, and armor, helmets, shields and rings add
+// their Defense and ArmorClass together. Potions and scrolls have a Kind
+// instead of a Slot, and are used up for their Effect, with Power dice and
+// a Radius.
+type Item struct {
+	Template   string
+	Slot       Slot
+	Damage     string
+	ToHitBonus int
+	Defense    string
+	ArmorClass int
+	Kind       string
+	Effect     string
+	Power      string
+	Radius     int
+}
+
+// Inventory is the items an entity carries, by entity ID, in the order they
+// were picked up. Carried items have no position of their own. Gold is the
+// gold pieces carried, which take up no room.
+type Inventory struct {
+	Items    []ecs.EntityID
+	Capacity int
+	Gold     int
+}
+
+// IsFull reports whether there is no room for another item.
+func (inv *Inventory) IsFull() bool {
+	return len(inv.Items) >= inv.Capacity
+}
+
+// Equipment is what an entity has equipped from its inventory. Slots holds
+// the item in each slot except rings, which are kept in Rings.
+type Equipment struct {
+	Slots map[Slot]ecs.EntityID
+	Rings []ecs.EntityID
+}
+
+// IsEquipped reports whether item is equipped in any slot.
+func (e *Equipment) IsEquipped(item ecs.EntityID) bool {
+	for _, id := range e.Slots {
+		if id == item {
+			return true
+		}
+	}
+	for _, id := range e.Rings {
+		if id == item {
+			return true
+		}
+	}
+	return false
+}
 
 type Name struct {
 	Label string
@@ -39,16 +159,19 @@ type Health struct {
 	CurrentHealth int
 }
 
+// MeleeWeapon describes the weapon an entity attacks with. Damage is dice
+// notation such as "2d6+3".
 type MeleeWeapon struct {
-	Name          string
-	MinimumDamage int
-	MaximumDamage int
-	ToHitBonus    int
+	Name       string
+	Damage     string
+	ToHitBonus int
 }
 
+// Armor describes what an entity wears. Defense is dice notation for the
+// damage the armor absorbs from each hit, and may be a flat number like "5".
 type Armor struct {
 	Name       string
-	Defense    int
+	Defense    string
 	ArmorClass int
 }
 
@@ -7,6 +7,12 @@ type GameData struct {
 	TileWidth    int
 	TileHeight   int
 	UIHeight     int
+	LevelWidth   int
+	LevelHeight  int
+
+	// DiagonalMovement switches between 4-way and 8-way movement for the
+	// player, monsters and pathfinding.
+	DiagonalMovement bool
 }
 
 // NewGameData creates a fully populated GameData Struct.
@@ -17,6 +23,10 @@ func NewGameData() GameData {
 		TileWidth:    16,
 		TileHeight:   16,
 		UIHeight:     10,
+		LevelWidth:   80,
+		LevelHeight:  50,
+
+		DiagonalMovement: false,
 	}
 
 	return g
@@ -22,7 +22,7 @@ func TestEventBus_Subscribe_and_Publish(t *testing.T) {
 	// Create and publish an attack event
 	attackerPos := &components.Position{X: 1, Y: 1}
 	defenderPos := &components.Position{X: 2, Y: 2}
-	attackEvent := NewAttackEvent(nil, nil, attackerPos, defenderPos, 5, true)
+	attackEvent := NewAttackEvent(1, 2, attackerPos, defenderPos, 5, true)
 	bus.Publish(attackEvent)
 
 	// Verify handler was called
@@ -56,7 +56,7 @@ func TestEventBus_MultipleSubscribers(t *testing.T) {
 	})
 
 	// Publish event
-	damageEvent := NewDamageEvent(nil, 10, "sword", false)
+	damageEvent := NewDamageEvent(2, 10, "sword", false)
 	bus.Publish(damageEvent)
 
 	// Verify both handlers were called
@@ -5,18 +5,19 @@ import (
 	"github.com/caustin/rrogue/components"
 )
 
-// AttackEvent represents an attack between two entities
+// AttackEvent represents an attack between two entities. Entities are
+// referenced by ID since they may be disposed before a handler runs.
 type AttackEvent struct {
 	BaseEvent
-	Attacker    *ecs.QueryResult
-	Defender    *ecs.QueryResult
+	Attacker    ecs.EntityID
+	Defender    ecs.EntityID
 	AttackerPos *components.Position
 	DefenderPos *components.Position
 	ToHitRoll   int
 	Hit         bool
 }
 
-func NewAttackEvent(attacker, defender *ecs.QueryResult, attackerPos, defenderPos *components.Position, toHitRoll int, hit bool) *AttackEvent {
+func NewAttackEvent(attacker, defender ecs.EntityID, attackerPos, defenderPos *components.Position, toHitRoll int, hit bool) *AttackEvent {
 	return &AttackEvent{
 		BaseEvent:   NewBaseEvent(AttackEventType),
 		Attacker:    attacker,
@@ -31,13 +32,13 @@ func NewAttackEvent(attacker, defender *ecs.QueryResult, attackerPos, defenderPo
 // DamageEvent represents damage being dealt
 type DamageEvent struct {
 	BaseEvent
-	Target       *ecs.QueryResult
+	Target       ecs.EntityID
 	DamageAmount int
 	DamageSource string
 	IsFatal      bool
 }
 
-func NewDamageEvent(target *ecs.QueryResult, damageAmount int, damageSource string, isFatal bool) *DamageEvent {
+func NewDamageEvent(target ecs.EntityID, damageAmount int, damageSource string, isFatal bool) *DamageEvent {
 	return &DamageEvent{
 		BaseEvent:    NewBaseEvent(DamageEventType),
 		Target:       target,
@@ -47,33 +48,38 @@ func NewDamageEvent(target *ecs.QueryResult, damageAmount int, damageSource stri
 	}
 }
 
-// DeathEvent represents an entity dying
+// DeathEvent represents an entity dying. A monster has already been disposed
+// by the time the event is handled, so its ID no longer resolves; Template
+// is the ID of the monster template it was made from, and is empty for the
+// player.
 type DeathEvent struct {
 	BaseEvent
-	Entity   *ecs.QueryResult
+	Entity   ecs.EntityID
 	Position *components.Position
 	IsPlayer bool
+	Template string
 }
 
-func NewDeathEvent(entity *ecs.QueryResult, position *components.Position, isPlayer bool) *DeathEvent {
+func NewDeathEvent(entity ecs.EntityID, position *components.Position, isPlayer bool, template string) *DeathEvent {
 	return &DeathEvent{
 		BaseEvent: NewBaseEvent(DeathEventType),
 		Entity:    entity,
 		Position:  position,
 		IsPlayer:  isPlayer,
+		Template:  template,
 	}
 }
 
 // MoveEvent represents entity movement
 type MoveEvent struct {
 	BaseEvent
-	Entity   *ecs.QueryResult
+	Entity   ecs.EntityID
 	FromPos  *components.Position
 	ToPos    *components.Position
 	IsPlayer bool
 }
 
-func NewMoveEvent(entity *ecs.QueryResult, fromPos, toPos *components.Position, isPlayer bool) *MoveEvent {
+func NewMoveEvent(entity ecs.EntityID, fromPos, toPos *components.Position, isPlayer bool) *MoveEvent {
 	return &MoveEvent{
 		BaseEvent: NewBaseEvent(MoveEventType),
 		Entity:    entity,
@@ -32,6 +32,18 @@ const (
 	// Map Events
 	TileBlockedEventType   EventType = "tile_blocked"
 	TileUnblockedEventType EventType = "tile_unblocked"
+
+	// Item Events
+	ItemUsedEventType       EventType = "item_used"
+	HealEventType           EventType = "heal"
+	TeleportEventType       EventType = "teleport"
+	MapRevealedEventType    EventType = "map_revealed"
+	ItemIdentifiedEventType EventType = "item_identified"
+	LootDroppedEventType    EventType = "loot_dropped"
+
+	// Experience Events
+	ExperienceGainedEventType EventType = "experience_gained"
+	LevelUpEventType          EventType = "level_up"
 )
 
 // BaseEvent provides common event functionality
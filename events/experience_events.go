@@ -0,0 +1,43 @@
+package events
+
+import (
+	"github.com/bytearena/ecs"
+)
+
+// ExperienceGainedEvent represents an entity earning experience, Source
+// being what it was earned for
+type ExperienceGainedEvent struct {
+	BaseEvent
+	Entity ecs.EntityID
+	Amount int
+	Source string
+}
+
+func NewExperienceGainedEvent(entity ecs.EntityID, amount int, source string) *ExperienceGainedEvent {
+	return &ExperienceGainedEvent{
+		BaseEvent: NewBaseEvent(ExperienceGainedEventType),
+		Entity:    entity,
+		Amount:    amount,
+		Source:    source,
+	}
+}
+
+// LevelUpEvent represents an entity reaching a new experience level, and
+// what it gained for it
+type LevelUpEvent struct {
+	BaseEvent
+	Entity       ecs.EntityID
+	Level        int
+	HealthGained int
+	ToHitGained  int
+}
+
+func NewLevelUpEvent(entity ecs.EntityID, level int, healthGained int, toHitGained int) *LevelUpEvent {
+	return &LevelUpEvent{
+		BaseEvent:    NewBaseEvent(LevelUpEventType),
+		Entity:       entity,
+		Level:        level,
+		HealthGained: healthGained,
+		ToHitGained:  toHitGained,
+	}
+}
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/components"
+)
+
+// ItemUsedEvent represents an entity using up a potion or scroll. The item
+// entity is gone by the time the event is handled, so its data and name
+// are carried along.
+type ItemUsedEvent struct {
+	BaseEvent
+	User     ecs.EntityID
+	Item     components.Item
+	ItemName string
+	Position *components.Position
+}
+
+func NewItemUsedEvent(user ecs.EntityID, item components.Item, itemName string, position *components.Position) *ItemUsedEvent {
+	return &ItemUsedEvent{
+		BaseEvent: NewBaseEvent(ItemUsedEventType),
+		User:      user,
+		Item:      item,
+		ItemName:  itemName,
+		Position:  position,
+	}
+}
+
+// HealEvent represents an entity regaining health
+type HealEvent struct {
+	BaseEvent
+	Target     ecs.EntityID
+	Amount     int
+	HealSource string
+}
+
+func NewHealEvent(target ecs.EntityID, amount int, healSource string) *HealEvent {
+	return &HealEvent{
+		BaseEvent:  NewBaseEvent(HealEventType),
+		Target:     target,
+		Amount:     amount,
+		HealSource: healSource,
+	}
+}
+
+// TeleportEvent represents an entity vanishing from one tile and appearing
+// on another of the same level
+type TeleportEvent struct {
+	BaseEvent
+	Entity  ecs.EntityID
+	FromPos *components.Position
+	ToPos   *components.Position
+}
+
+func NewTeleportEvent(entity ecs.EntityID, fromPos, toPos *components.Position) *TeleportEvent {
+	return &TeleportEvent{
+		BaseEvent: NewBaseEvent(TeleportEventType),
+		Entity:    entity,
+		FromPos:   fromPos,
+		ToPos:     toPos,
+	}
+}
+
+// MapRevealedEvent represents the whole of a level being revealed
+type MapRevealedEvent struct {
+	BaseEvent
+	Depth int
+}
+
+func NewMapRevealedEvent(depth int) *MapRevealedEvent {
+	return &MapRevealedEvent{
+		BaseEvent: NewBaseEvent(MapRevealedEventType),
+		Depth:     depth,
+	}
+}
+
+// ItemIdentifiedEvent represents the player learning what items made from a
+// template really are
+type ItemIdentifiedEvent struct {
+	BaseEvent
+	Template string
+	Name     string
+}
+
+func NewItemIdentifiedEvent(template, name string) *ItemIdentifiedEvent {
+	return &ItemIdentifiedEvent{
+		BaseEvent: NewBaseEvent(ItemIdentifiedEventType),
+		Template:  template,
+		Name:      name,
+	}
+}
+
+// LootDroppedEvent represents a dead monster leaving things on the tile
+// where it died: a corpse, if it has one, and the items and gold rolled
+// from its loot table
+type LootDroppedEvent struct {
+	BaseEvent
+	Source   string
+	Position *components.Position
+	Corpse   bool
+	Items    []ecs.EntityID
+	Gold     int
+}
+
+func NewLootDroppedEvent(source string, position *components.Position, corpse bool, items []ecs.EntityID, gold int) *LootDroppedEvent {
+	return &LootDroppedEvent{
+		BaseEvent: NewBaseEvent(LootDroppedEventType),
+		Source:    source,
+		Position:  position,
+		Corpse:    corpse,
+		Items:     items,
+		Gold:      gold,
+	}
+}
@@ -0,0 +1,117 @@
+package game
+
+import "fmt"
+
+// CommandKind is the sort of thing the player does on their turn.
+type CommandKind int
+
+const (
+	CommandMove      CommandKind = iota // Step, or attack, by DX, DY
+	CommandRun                          // Keep moving by DX, DY until something comes up
+	CommandAttack                       // Attack whatever is at DX, DY without moving
+	CommandWait                         // Let a turn pass
+	CommandOpenDoor                     // Open a door next to the player
+	CommandCloseDoor                    // Close a door next to the player
+	CommandDescend                      // Take the stairs down
+	CommandAscend                       // Take the stairs up
+	CommandPickUp                       // Pick up what is lying under the player
+	CommandDrop                         // Drop the inventory item Item
+	CommandEquip                        // Wield or put on the inventory item Item
+	CommandRemove                       // Put away or take off the inventory item Item
+	CommandInventory                    // List what the player is carrying
+	CommandUse                          // Quaff or read the inventory item Item
+)
+
+// commandKindNames are how command kinds are written in replay files.
+var commandKindNames = []string{
+	"move", "run", "attack", "wait", "open_door", "close_door", "descend", "ascend",
+	"pick_up", "drop", "equip", "remove", "inventory", "use",
+}
+
+// NeedsItem reports whether commands of this kind act on an inventory item.
+func (k CommandKind) NeedsItem() bool {
+	return k == CommandDrop || k == CommandEquip || k == CommandRemove || k == CommandUse
+}
+
+// MarshalText writes the kind by name, so replay files stay readable.
+func (k CommandKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(commandKindNames) {
+		return nil, fmt.Errorf("unknown command kind %d", int(k))
+	}
+	return []byte(commandKindNames[k]), nil
+}
+
+// UnmarshalText reads a kind written by MarshalText.
+func (k *CommandKind) UnmarshalText(text []byte) error {
+	for i, name := range commandKindNames {
+		if name == string(text) {
+			*k = CommandKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown command %q", text)
+}
+
+// Command is one thing the player does. DX and DY are only used by moves,
+// runs and attacks, and Item, the index of an item in the player's
+// inventory, by commands that act on one.
+type Command struct {
+	Kind CommandKind `json:"kind"`
+	DX   int         `json:"dx,omitempty"`
+	DY   int         `json:"dy,omitempty"`
+	Item int         `json:"item,omitempty"`
+}
+
+// MoveCommand steps the player by dx, dy, attacking whatever is there.
+func MoveCommand(dx, dy int) Command {
+	return Command{Kind: CommandMove, DX: dx, DY: dy}
+}
+
+// RunCommand moves the player by dx, dy until a monster comes into view or
+// the corridor opens up.
+func RunCommand(dx, dy int) Command {
+	return Command{Kind: CommandRun, DX: dx, DY: dy}
+}
+
+// AttackCommand attacks the tile at dx, dy from the player, never moving.
+func AttackCommand(dx, dy int) Command {
+	return Command{Kind: CommandAttack, DX: dx, DY: dy}
+}
+
+// ItemCommand acts on the inventory item at index.
+func ItemCommand(kind CommandKind, index int) Command {
+	return Command{Kind: kind, Item: index}
+}
+
+// CommandSource decides what the player does next. ok is false while there
+// is nothing to do yet, such as when no key has been pressed.
+type CommandSource interface {
+	NextCommand() (cmd Command, ok bool)
+}
+
+// ScriptedCommands hands out a fixed list of commands in order, to drive
+// headless games in tests and batch simulations.
+type ScriptedCommands struct {
+	commands []Command
+	next     int
+}
+
+// NewScriptedCommands creates a source that plays back commands in order.
+func NewScriptedCommands(commands ...Command) *ScriptedCommands {
+	return &ScriptedCommands{commands: commands}
+}
+
+// NextCommand returns the next command in the script.
+func (s *ScriptedCommands) NextCommand() (Command, bool) {
+	if s.Done() {
+		return Command{}, false
+	}
+	cmd := s.commands[s.next]
+	s.next++
+	return cmd, true
+}
+
+// Done reports whether every command has been handed out.
+func (s *ScriptedCommands) Done() bool {
+	return s.next >= len(s.commands)
+}
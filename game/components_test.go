@@ -2,6 +2,7 @@ package game
 
 import (
 	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/utils"
 	"testing"
 )
 
@@ -60,6 +61,49 @@ func TestManhattanDistance(t *testing.T) {
 	}
 }
 
+func TestChebyshevDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		pos1     components.Position
+		pos2     components.Position
+		expected int
+	}{
+		{
+			name:     "same position",
+			pos1:     components.Position{X: 5, Y: 5},
+			pos2:     components.Position{X: 5, Y: 5},
+			expected: 0,
+		},
+		{
+			name:     "adjacent horizontal",
+			pos1:     components.Position{X: 5, Y: 5},
+			pos2:     components.Position{X: 6, Y: 5},
+			expected: 1,
+		},
+		{
+			name:     "adjacent diagonal",
+			pos1:     components.Position{X: 5, Y: 5},
+			pos2:     components.Position{X: 4, Y: 6},
+			expected: 1,
+		},
+		{
+			name:     "longer side wins",
+			pos1:     components.Position{X: 0, Y: 0},
+			pos2:     components.Position{X: 3, Y: -7},
+			expected: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.pos1.GetChebyshevDistance(&tt.pos2)
+			if result != tt.expected {
+				t.Errorf("GetChebyshevDistance() = %d, expected %d", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestPositionEquality(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -174,10 +218,9 @@ func TestHealthComponent(t *testing.T) {
 
 func TestMeleeWeaponComponent(t *testing.T) {
 	weapon := components.MeleeWeapon{
-		Name:          "Test Sword",
-		MinimumDamage: 5,
-		MaximumDamage: 15,
-		ToHitBonus:    3,
+		Name:       "Test Sword",
+		Damage:     "1d11+4",
+		ToHitBonus: 3,
 	}
 
 	// Test that weapon properties are set correctly
@@ -185,28 +228,29 @@ func TestMeleeWeaponComponent(t *testing.T) {
 		t.Errorf("Expected weapon name 'Test Sword', got '%s'", weapon.Name)
 	}
 
-	if weapon.MinimumDamage != 5 {
-		t.Errorf("Expected minimum damage 5, got %d", weapon.MinimumDamage)
+	if weapon.ToHitBonus != 3 {
+		t.Errorf("Expected to hit bonus 3, got %d", weapon.ToHitBonus)
 	}
 
-	if weapon.MaximumDamage != 15 {
-		t.Errorf("Expected maximum damage 15, got %d", weapon.MaximumDamage)
+	// Test damage dice parse to the expected range
+	damage, err := utils.ParseDice(weapon.Damage)
+	if err != nil {
+		t.Fatalf("Weapon damage %q did not parse: %v", weapon.Damage, err)
 	}
 
-	if weapon.ToHitBonus != 3 {
-		t.Errorf("Expected to hit bonus 3, got %d", weapon.ToHitBonus)
+	if damage.Min() != 5 {
+		t.Errorf("Expected minimum damage 5, got %d", damage.Min())
 	}
 
-	// Test damage range is valid
-	if weapon.MinimumDamage > weapon.MaximumDamage {
-		t.Error("Minimum damage should not exceed maximum damage")
+	if damage.Max() != 15 {
+		t.Errorf("Expected maximum damage 15, got %d", damage.Max())
 	}
 }
 
 func TestArmorComponent(t *testing.T) {
 	armor := components.Armor{
 		Name:       "Test Plate",
-		Defense:    10,
+		Defense:    "10",
 		ArmorClass: 15,
 	}
 
@@ -215,8 +259,8 @@ func TestArmorComponent(t *testing.T) {
 		t.Errorf("Expected armor name 'Test Plate', got '%s'", armor.Name)
 	}
 
-	if armor.Defense != 10 {
-		t.Errorf("Expected defense 10, got %d", armor.Defense)
+	if armor.Defense != "10" {
+		t.Errorf("Expected defense 10, got %s", armor.Defense)
 	}
 
 	if armor.ArmorClass != 15 {
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"github.com/caustin/rrogue/components"
+	level2 "github.com/caustin/rrogue/level"
+)
+
+// OpenDoor has the player open the door at x,y. A locked door is unlocked
+// with one of the player's keys if they have any. Returns true if the
+// player's turn was used.
+func OpenDoor(g *Game, x int, y int) bool {
+	l := g.Map.CurrentLevel
+	tile := l.Tiles[l.GetIndexFromXY(x, y)]
+	if tile.TileType != level2.DOOR {
+		return false
+	}
+
+	for _, result := range g.World.QueryPlayers() {
+		pos := g.World.GetPosition(result)
+
+		switch tile.DoorState {
+		case level2.DoorOpen:
+			g.Systems.UI.AddMessage("That door is already open.\n", "info")
+			return false
+		case level2.DoorLocked:
+			keyring := g.World.GetKeyring(result)
+			if keyring.Keys == 0 {
+				g.Systems.UI.AddMessage("The door is locked.\n", "info")
+				return false
+			}
+			keyring.Keys--
+			l.UnlockDoor(x, y)
+			g.Systems.UI.AddMessage("You unlock the door.\n", "info")
+		}
+
+		l.OpenDoor(x, y)
+		l.PlayerVisible.Compute(l, pos.X, pos.Y, 8)
+		return true
+	}
+
+	return false
+}
+
+// OpenAdjacentDoor opens the first closed or locked door next to the player.
+func OpenAdjacentDoor(g *Game) bool {
+	l := g.Map.CurrentLevel
+	for _, pos := range playerNeighbours(g) {
+		tile := l.Tiles[l.GetIndexFromXY(pos.X, pos.Y)]
+		if tile.TileType == level2.DOOR && tile.DoorState != level2.DoorOpen {
+			return OpenDoor(g, pos.X, pos.Y)
+		}
+	}
+	g.Systems.UI.AddMessage("There is no door to open here.\n", "info")
+	return false
+}
+
+// CloseAdjacentDoor closes the first open door next to the player. Doors
+// with something standing in them can't be closed.
+func CloseAdjacentDoor(g *Game) bool {
+	l := g.Map.CurrentLevel
+	blocked := false
+	for _, pos := range playerNeighbours(g) {
+		tile := l.Tiles[l.GetIndexFromXY(pos.X, pos.Y)]
+		if tile.TileType != level2.DOOR || tile.DoorState != level2.DoorOpen {
+			continue
+		}
+		if l.CloseDoor(pos.X, pos.Y) {
+			for _, result := range g.World.QueryPlayers() {
+				player := g.World.GetPosition(result)
+				l.PlayerVisible.Compute(l, player.X, player.Y, 8)
+			}
+			return true
+		}
+		blocked = true
+	}
+
+	if blocked {
+		g.Systems.UI.AddMessage("Something is in the way.\n", "info")
+	} else {
+		g.Systems.UI.AddMessage("There is no door to close here.\n", "info")
+	}
+	return false
+}
+
+// playerNeighbours returns the in bounds positions next to the player.
+func playerNeighbours(g *Game) []components.Position {
+	l := g.Map.CurrentLevel
+	neighbours := make([]components.Position, 0, 4)
+	for _, result := range g.World.QueryPlayers() {
+		pos := g.World.GetPosition(result)
+		for _, d := range []struct{ dx, dy int }{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			n := components.Position{X: pos.X + d.dx, Y: pos.Y + d.dy}
+			if l.InBounds(n.X, n.Y) {
+				neighbours = append(neighbours, n)
+			}
+		}
+	}
+	return neighbours
+}
+
+// pickUpKeys adds any keys lying at pos to the player's keyring.
+func pickUpKeys(g *Game, pos *components.Position) {
+	for _, key := range g.World.QueryKeys() {
+		if !g.World.GetPosition(key).IsEqual(pos) {
+			continue
+		}
+		for _, result := range g.World.QueryPlayers() {
+			g.World.GetKeyring(result).Keys++
+		}
+		g.World.DisposeEntity(key)
+		g.Systems.UI.AddMessage("You pick up a key.\n", "info")
+	}
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/caustin/rrogue/events"
+)
+
+func TestKillsLevelThePlayerUp(t *testing.T) {
+	g := NewHeadlessGame(21, NewScriptedCommands())
+	player := g.World.QueryPlayers()[0]
+	experience := g.World.GetExperience(player)
+	health := g.World.GetHealth(player)
+	toHit := g.World.GetMeleeWeapon(player).ToHitBonus
+	if experience.Level != 1 || experience.XP != 0 {
+		t.Fatalf("player starts at level %d with %d XP", experience.Level, experience.XP)
+	}
+
+	var levels []int
+	g.EventBus.Subscribe(events.LevelUpEventType, func(e events.Event) { levels = append(levels, e.(*events.LevelUpEvent).Level) })
+
+	// Two orcs, at 12 XP each, are enough for level 2 but not level 3
+	killed := 0
+	for _, monster := range g.World.QueryMonsters() {
+		if g.World.GetMonster(monster).Template != "orc" || killed == 2 {
+			continue
+		}
+		g.EventBus.Publish(events.NewDamageEvent(g.World.ID(monster), 1000, "test", true))
+		killed++
+	}
+	if killed != 2 {
+		t.Fatalf("found %d orcs to kill, expected 2", killed)
+	}
+
+	if experience.XP != 24 || experience.Level != 2 || len(levels) != 1 || levels[0] != 2 {
+		t.Errorf("after killing two orcs, level %d with %d XP and level ups %v", experience.Level, experience.XP, levels)
+	}
+	if health.MaxHealth != 38 || health.CurrentHealth != 38 {
+		t.Errorf("after levelling up, health is %d of %d, expected 38 of 38", health.CurrentHealth, health.MaxHealth)
+	}
+	if bonus := g.World.GetMeleeWeapon(player).ToHitBonus; bonus != toHit+1 {
+		t.Errorf("after levelling up, to hit bonus is %d, expected %d", bonus, toHit+1)
+	}
+
+	// The bonus is earned, not part of the weapon, so it survives a change of weapon
+	UseInventory(g, ItemCommand(CommandRemove, 0))
+	if bonus := g.World.GetMeleeWeapon(player).ToHitBonus; bonus != 1 {
+		t.Errorf("fighting unarmed at level 2, to hit bonus is %d, expected 1", bonus)
+	}
+}
@@ -1,11 +1,17 @@
 package game
 
 import (
+	"fmt"
+	"github.com/caustin/rrogue/assets"
 	"github.com/caustin/rrogue/config"
 	"github.com/caustin/rrogue/events"
+	"github.com/caustin/rrogue/level"
 	"github.com/caustin/rrogue/systems"
+	"github.com/caustin/rrogue/templates"
+	"github.com/caustin/rrogue/utils"
 	"github.com/caustin/rrogue/world"
 	"github.com/hajimehoshi/ebiten/v2"
+	"log"
 )
 
 // Game holds all data the entire game will need.
@@ -15,25 +21,94 @@ type Game struct {
 	EventBus      *events.EventBus
 	Systems       *systems.SystemRegistry
 	GameData      config.GameData
+	Camera        *level.Camera
 	Turn          TurnState
 	TurnCounter   int
 	AutoMoveState *AutoMoveState
+	Seed          int64
+	RNG           *utils.SeededRNG
+	SavePath      string // Where the game is saved on quit; empty means it isn't
+	Assets        assets.Provider
+	Input         CommandSource
+	Recording     *Replay       // Every command given so far, if the run is being recorded
+	ReplayPath    string        // Where the recording is written on quit; empty means it isn't
+	Playback      *ReplayPlayer // The replay being played back, if any
 }
 
-// NewGame creates a new Game Object and initializes the data
+// NewGame creates a new Game Object with a fresh random seed
 func NewGame() *Game {
+	return NewGameWithSeed(utils.NewSeed())
+}
+
+// NewGameWithSeed creates a new Game Object and initializes the data.
+// Every random decision in the run is drawn from the seed, so two games
+// started with the same seed play out identically.
+func NewGameWithSeed(seed int64) *Game {
+	g := newGame(seed, assets.Files{})
+	g.Input = loadKeyboard(g)
+	return g
+}
+
+// NewHeadlessGame creates a game that loads no images and takes the
+// player's commands from input instead of the keyboard. It is run with Step
+// rather than by ebiten, so it needs no window; tests and batch simulations
+// use it to play out a seed. Runs happen without waiting between steps.
+func NewHeadlessGame(seed int64, input CommandSource) *Game {
+	g := newGame(seed, assets.Null{})
+	g.Input = input
+	g.AutoMoveState = &AutoMoveState{}
+	return g
+}
+
+// newGame creates a game from the seed, loading images through provider.
+func newGame(seed int64, provider assets.Provider) *Game {
 	g := &Game{}
-	g.Map = NewGameMap()
+	g.Assets = provider
+	g.Seed = seed
+	g.Recording = NewReplay(seed)
+	g.RNG = utils.NewSeededRNG(seed)
+	g.Map = NewGameMap(g.RNG, loadTileImages(provider))
 	g.GameData = config.NewGameData()
+	g.Camera = level.NewCamera(g.GameData.ScreenWidth, g.GameData.ScreenHeight-g.GameData.UIHeight)
 
 	// Create world service
-	g.World = world.NewGameWorld(g.Map.CurrentLevel)
+	g.World = world.NewGameWorld(g.Map.CurrentLevel, g.RNG, loadTemplates(), g.Assets)
+	g.wireSystems()
+
+	g.Turn = WaitingForPlayerInput
+	g.TurnCounter = 0
+
+	// Show the seed so it can be included in bug reports
+	g.Systems.UI.AddMessage(fmt.Sprintf("Seed: %d\n", seed), "info")
+	return g
+}
+
+// loadTileImages loads the images level tiles are drawn with through provider.
+func loadTileImages(provider assets.Provider) *level.TileImages {
+	images, err := level.LoadTileImages(provider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return images
+}
 
+// loadTemplates reads the monster, weapon and armor definitions.
+func loadTemplates() *templates.Registry {
+	registry, err := templates.Load("assets/data")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return registry
+}
+
+// wireSystems creates the event bus and systems for the game's world and
+// connects them to the game.
+func (g *Game) wireSystems() {
 	// Create event bus
 	g.EventBus = events.NewEventBus()
 
 	// Create and initialize all systems
-	g.Systems = systems.NewSystemRegistry(g.World, g.EventBus)
+	g.Systems = systems.NewSystemRegistry(g.World, g.EventBus, g.RNG)
 
 	// Register all event handlers
 	g.Systems.RegisterAllHandlers()
@@ -55,16 +130,31 @@ func NewGame() *Game {
 		g.Systems.MapBridge.SetGameReference(unblockTileFunc)
 	}
 
+	// Teleporting and mapping act on whichever level the player is on
+	g.Systems.Effects.SetLevel(func() level.Level {
+		return g.Map.CurrentLevel
+	})
+
 	// Temporary event handlers are no longer needed -
 	// MapBridge handles tile cleanup and GameStateSystem handles game over
-
-	g.Turn = WaitingForPlayerInput
-	g.TurnCounter = 0
-	return g
 }
 
 // Update is called each tic.
 func (g *Game) Update() error {
+	if ebiten.IsWindowBeingClosed() {
+		return g.quit()
+	}
+	if g.Playback != nil {
+		g.Playback.HandleControls()
+	}
+	g.Step()
+	return nil
+}
+
+// Step advances the game by one tic: the player's command if there is one,
+// or the monsters' turn. Update calls it each tic; headless games call it
+// directly.
+func (g *Game) Step() {
 	switch g.Turn {
 	case WaitingForPlayerInput:
 		if TakePlayerAction(g) {
@@ -87,19 +177,22 @@ func (g *Game) Update() error {
 			// Fallback for during migration
 			g.Turn = WaitingForPlayerInput
 		}
+	case GameOver:
+		// Nothing happens until the window is closed
 	default:
 		panic("unhandled default case")
 	}
-
-	return nil
-
 }
 
 // Draw is called each draw cycle and is where we will blit.
 func (g *Game) Draw(screen *ebiten.Image) {
 	//Draw the Map
 	level := g.Map.CurrentLevel
-	level.DrawLevel(screen, g.GameData)
+	for _, p := range g.World.QueryPlayers() {
+		pos := g.World.GetPosition(p)
+		g.Camera.Follow(pos.X, pos.Y, level)
+	}
+	level.DrawLevel(screen, g.GameData, g.Camera)
 	ProcessRenderables(g, level, screen)
 	ProcessUserLog(g, screen)
 	ProcessHUD(g, screen)
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/caustin/rrogue/level"
+)
+
+// TestMain runs the tests from the repository root, where templates, vaults
+// and keybindings are loaded from, as they are when the game is started.
+func TestMain(m *testing.M) {
+	if err := os.Chdir(".."); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+// playScript runs a headless game until its script is used up and the
+// monsters have had their last turn.
+func playScript(t *testing.T, seed int64, commands ...Command) *Game {
+	t.Helper()
+	script := NewScriptedCommands(commands...)
+	g := NewHeadlessGame(seed, script)
+	for steps := 0; !script.Done() || g.Turn != WaitingForPlayerInput; steps++ {
+		if g.Turn == GameOver {
+			break
+		}
+		if steps > 10*len(commands) {
+			t.Fatalf("seed %d: script still running after %d steps", seed, steps)
+		}
+		g.Step()
+	}
+	return g
+}
+
+func TestHeadlessGame(t *testing.T) {
+	commands := []Command{
+		MoveCommand(1, 0), MoveCommand(1, 0), MoveCommand(0, 1), {Kind: CommandWait},
+		MoveCommand(-1, 0), MoveCommand(0, -1), {Kind: CommandWait}, MoveCommand(-1, 0),
+	}
+	for _, seed := range []int64{1, 2, 3} {
+		g := playScript(t, seed, commands...)
+		if g.TurnCounter != len(commands) {
+			t.Errorf("seed %d: %d turns taken, expected %d", seed, g.TurnCounter, len(commands))
+		}
+
+		player, _ := g.World.GetEntity(g.World.ID(g.World.QueryPlayers()[0]))
+		if g.World.GetRenderable(player).Image != nil {
+			t.Errorf("seed %d: headless game loaded the player's sprite", seed)
+		}
+
+		again := playScript(t, seed, commands...)
+		if !reflect.DeepEqual(g.World.Snapshot(), again.World.Snapshot()) {
+			t.Errorf("seed %d: the same script played out differently", seed)
+		}
+		if g.RNG.State() != again.RNG.State() {
+			t.Errorf("seed %d: RNG state %d, then %d", seed, g.RNG.State(), again.RNG.State())
+		}
+	}
+}
+
+func TestAttackingCantReachAroundCorners(t *testing.T) {
+	g := NewHeadlessGame(4, NewScriptedCommands())
+	g.GameData.DiagonalMovement = true
+	l := g.Map.CurrentLevel
+	pos := g.World.GetPosition(g.World.QueryPlayers()[0])
+
+	// A monster diagonally down and right, with a wall on the corner between
+	monster := g.World.QueryMonsters()[0]
+	l.Tiles[l.GetIndexFromXY(g.World.GetPosition(monster).X, g.World.GetPosition(monster).Y)].Blocked = false
+	g.World.MoveEntity(monster, pos.X+1, pos.Y+1)
+	corner := l.Tiles[l.GetIndexFromXY(pos.X+1, pos.Y)]
+	corner.TileType, corner.Blocked = level.WALL, true
+
+	if attackAdjacent(g, 1, 1) {
+		t.Error("attacked around the corner of a wall")
+	}
+
+	corner.TileType, corner.Blocked = level.FLOOR, false
+	if !attackAdjacent(g, 1, 1) {
+		t.Error("could not attack diagonally across open floor")
+	}
+}
+
+func TestBumpingALockedDoorWithoutAKeyTakesNoTurn(t *testing.T) {
+	script := NewScriptedCommands(MoveCommand(1, 0))
+	g := NewHeadlessGame(4, script)
+	l := g.Map.CurrentLevel
+	player := g.World.QueryPlayers()[0]
+	pos := g.World.GetPosition(player)
+	door := l.Tiles[l.GetIndexFromXY(pos.X+1, pos.Y)]
+	door.TileType, door.DoorState, door.Blocked = level.DOOR, level.DoorLocked, true
+	g.World.GetKeyring(player).Keys = 0
+
+	g.Step()
+	if g.TurnCounter != 0 || g.Turn != WaitingForPlayerInput {
+		t.Errorf("bumping a locked door without a key took a turn: turn %d, state %v", g.TurnCounter, g.Turn)
+	}
+	if door.DoorState != level.DoorLocked {
+		t.Error("the locked door opened without a key")
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"log"
 
 	"github.com/caustin/rrogue/fonts"
+	"github.com/caustin/rrogue/systems"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
@@ -20,7 +20,7 @@ var hudFont font.Face = nil
 
 func ProcessHUD(g *Game, screen *ebiten.Image) {
 	if hudImg == nil {
-		hudImg, _, hudErr = ebitenutil.NewImageFromFile("assets/UIPanel.png")
+		hudImg, hudErr = g.Assets.Image("assets/UIPanel.png")
 		if hudErr != nil {
 			log.Fatal(hudErr)
 		}
@@ -60,14 +60,24 @@ func ProcessHUD(g *Game, screen *ebiten.Image) {
 		acText := fmt.Sprintf("Armor Class: %d", ac.ArmorClass)
 		text.Draw(screen, acText, mplusNormalFont, fontX, fontY, color.White)
 		fontY += 16
-		defText := fmt.Sprintf("Defense: %d", ac.Defense)
+		defText := fmt.Sprintf("Defense: %s", ac.Defense)
 		text.Draw(screen, defText, mplusNormalFont, fontX, fontY, color.White)
 		fontY += 16
 		wpn := g.World.GetMeleeWeapon(p)
-		dmg := fmt.Sprintf("Damage: %d - %d", wpn.MinimumDamage, wpn.MaximumDamage)
+		dmg := fmt.Sprintf("Damage: %s", wpn.Damage)
 		text.Draw(screen, dmg, mplusNormalFont, fontX, fontY, color.White)
 		fontY += 16
 		bonus := fmt.Sprintf("To Hit Bonus: %d", wpn.ToHitBonus)
 		text.Draw(screen, bonus, mplusNormalFont, fontX, fontY, color.White)
+		fontY += 16
+		xp := g.World.GetExperience(p)
+		level := fmt.Sprintf("Level: %d   XP: %d / %d", xp.Level, xp.XP, systems.XPForLevel(xp.Level+1))
+		text.Draw(screen, level, mplusNormalFont, fontX, fontY, color.White)
+		fontY += 16
+		depth := fmt.Sprintf("Depth: %d", g.Map.CurrentDepth)
+		text.Draw(screen, depth, mplusNormalFont, fontX, fontY, color.White)
+		fontY += 16
+		keys := fmt.Sprintf("Keys: %d   Gold: %d", g.World.GetKeyring(p).Keys, g.World.GetInventory(p).Gold)
+		text.Draw(screen, keys, mplusNormalFont, fontX, fontY, color.White)
 	}
 }
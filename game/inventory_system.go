@@ -0,0 +1,27 @@
+package game
+
+// UseInventory carries out one of the player's inventory commands,
+// returning true if it took a turn. Listing the inventory never does.
+func UseInventory(g *Game, cmd Command) bool {
+	inventory := g.Systems.Inventory
+	for _, player := range g.World.QueryPlayers() {
+		switch cmd.Kind {
+		case CommandPickUp:
+			return inventory.PickUp(player)
+		case CommandDrop:
+			return inventory.Drop(player, cmd.Item)
+		case CommandEquip:
+			return inventory.Equip(player, cmd.Item)
+		case CommandRemove:
+			return inventory.Remove(player, cmd.Item)
+		case CommandUse:
+			return inventory.Use(player, cmd.Item)
+		case CommandInventory:
+			for _, line := range inventory.Describe(player) {
+				g.Systems.UI.AddMessage(line, "info")
+			}
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/events"
+)
+
+func TestInventoryPickUpEquipAndDrop(t *testing.T) {
+	g := NewHeadlessGame(5, NewScriptedCommands())
+	player := g.World.QueryPlayers()[0]
+	pos := g.World.GetPosition(player)
+	inventory := g.World.GetInventory(player)
+	if len(inventory.Items) != 2 || g.World.GetMeleeWeapon(player).Name != "Battle Axe" {
+		t.Fatalf("player starts with %d items and a %s", len(inventory.Items), g.World.GetMeleeWeapon(player).Name)
+	}
+
+	if _, ok := g.World.SpawnItem("short_sword", pos.X, pos.Y); !ok {
+		t.Fatal("SpawnItem could not make a short sword")
+	}
+	if _, ok := g.World.SpawnItem("buckler", pos.X, pos.Y); !ok {
+		t.Fatal("SpawnItem could not make a buckler")
+	}
+	armorClass := g.World.GetArmor(player).ArmorClass
+
+	if !UseInventory(g, Command{Kind: CommandPickUp}) {
+		t.Fatal("picking up took no turn")
+	}
+	if len(inventory.Items) != 4 || len(g.World.ItemsAt(pos.X, pos.Y)) != 0 {
+		t.Fatalf("after picking up, carrying %d items with %d left on the floor", len(inventory.Items), len(g.World.ItemsAt(pos.X, pos.Y)))
+	}
+
+	UseInventory(g, ItemCommand(CommandEquip, 2))
+	UseInventory(g, ItemCommand(CommandEquip, 3))
+	if weapon := g.World.GetMeleeWeapon(player); weapon.Name != "Short Sword" || weapon.Damage != "1d5+1" {
+		t.Errorf("after wielding the short sword, weapon = %+v", weapon)
+	}
+	if armor := g.World.GetArmor(player); armor.ArmorClass != armorClass+2 || armor.Defense != "15+1d2" {
+		t.Errorf("after putting on the buckler, armor = %+v", armor)
+	}
+
+	if !UseInventory(g, ItemCommand(CommandDrop, 2)) {
+		t.Fatal("dropping took no turn")
+	}
+	if weapon := g.World.GetMeleeWeapon(player); weapon.Name != "Fists" {
+		t.Errorf("after dropping the wielded sword, weapon = %+v", weapon)
+	}
+	if items := g.World.ItemsAt(pos.X, pos.Y); len(items) != 1 || g.World.GetName(items[0]).Label != "Short Sword" {
+		t.Errorf("after dropping, %d items on the floor", len(items))
+	}
+
+	if UseInventory(g, ItemCommand(CommandEquip, 9)) {
+		t.Error("equipping a missing item took a turn")
+	}
+}
+
+func TestUsingConsumables(t *testing.T) {
+	g := NewHeadlessGame(8, NewScriptedCommands())
+	player := g.World.QueryPlayers()[0]
+	pos := g.World.GetPosition(player)
+	for _, id := range []string{"healing_potion", "mapping_scroll", "teleport_scroll"} {
+		if _, ok := g.World.SpawnItem(id, pos.X, pos.Y); !ok {
+			t.Fatalf("SpawnItem could not make a %s", id)
+		}
+	}
+	UseInventory(g, Command{Kind: CommandPickUp})
+
+	var healed, revealed, teleported bool
+	g.EventBus.Subscribe(events.HealEventType, func(events.Event) { healed = true })
+	g.EventBus.Subscribe(events.MapRevealedEventType, func(events.Event) { revealed = true })
+	g.EventBus.Subscribe(events.TeleportEventType, func(events.Event) { teleported = true })
+
+	health := g.World.GetHealth(player)
+	health.CurrentHealth = 5
+	if !UseInventory(g, ItemCommand(CommandUse, 2)) {
+		t.Fatal("quaffing took no turn")
+	}
+	if !healed || health.CurrentHealth <= 5 || health.CurrentHealth > health.MaxHealth {
+		t.Errorf("after quaffing, healed %v with %d of %d health", healed, health.CurrentHealth, health.MaxHealth)
+	}
+	if inventory := g.World.GetInventory(player); len(inventory.Items) != 4 {
+		t.Errorf("after quaffing, carrying %d items, expected the potion to be used up", len(inventory.Items))
+	}
+
+	UseInventory(g, ItemCommand(CommandUse, 2))
+	if !revealed {
+		t.Error("reading the mapping scroll published no MapRevealedEvent")
+	}
+	for i, tile := range g.Map.CurrentLevel.Tiles {
+		if !tile.IsRevealed {
+			t.Fatalf("tile %d is still hidden after reading the mapping scroll", i)
+		}
+	}
+
+	from := *pos
+	UseInventory(g, ItemCommand(CommandUse, 2))
+	l := g.Map.CurrentLevel
+	if !teleported || from == *pos {
+		t.Errorf("after reading the teleport scroll, still at %v", *pos)
+	}
+	if l.Tiles[l.GetIndexFromXY(from.X, from.Y)].Blocked || !l.Tiles[l.GetIndexFromXY(pos.X, pos.Y)].Blocked {
+		t.Error("teleporting left the tiles blocked where the player was, not where they are")
+	}
+
+	if UseInventory(g, ItemCommand(CommandUse, 0)) {
+		t.Error("using the battle axe took a turn")
+	}
+}
+
+func TestIdentifyingItems(t *testing.T) {
+	g := NewHeadlessGame(13, NewScriptedCommands())
+	player := g.World.QueryPlayers()[0]
+	pos := g.World.GetPosition(player)
+	var ids []ecs.EntityID
+	for _, template := range []string{"healing_potion", "healing_potion", "fire_scroll", "identify_scroll"} {
+		id, _ := g.World.SpawnItem(template, pos.X, pos.Y)
+		ids = append(ids, id)
+	}
+	name := func(id ecs.EntityID) string {
+		item, _ := g.World.GetEntity(id)
+		return g.World.GetName(item).Label
+	}
+
+	looks := g.World.Knowledge().Appearances
+	if name(ids[0]) != looks["healing_potion"] || name(ids[2]) != looks["fire_scroll"] {
+		t.Fatalf("unidentified items are named %q and %q, expected %q and %q",
+			name(ids[0]), name(ids[2]), looks["healing_potion"], looks["fire_scroll"])
+	}
+	if other := NewHeadlessGame(13, NewScriptedCommands()).World.Knowledge().Appearances; !reflect.DeepEqual(other, looks) {
+		t.Errorf("the same seed shuffled appearances %v, then %v", looks, other)
+	}
+
+	UseInventory(g, Command{Kind: CommandPickUp})
+	UseInventory(g, ItemCommand(CommandUse, 2))
+	if name(ids[1]) != "Potion of Healing" || !g.World.IsIdentified("healing_potion") {
+		t.Errorf("after quaffing a healing potion, the other one is named %q", name(ids[1]))
+	}
+	if g.World.IsIdentified("fire_scroll") {
+		t.Error("fire scroll identified before it was used or identified")
+	}
+
+	// The identify scroll is the last item, after the other potion and the fire scroll
+	UseInventory(g, ItemCommand(CommandUse, 4))
+	if name(ids[2]) != "Scroll of Fire" {
+		t.Errorf("after reading identify, the fire scroll is named %q", name(ids[2]))
+	}
+	if known := g.World.Knowledge().Identified; !known["fire_scroll"] || !known["identify_scroll"] {
+		t.Errorf("identified = %v", known)
+	}
+}
@@ -0,0 +1,342 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// KeymapFile is where the game reads its key bindings from.
+const KeymapFile = "assets/data/keybindings.json"
+
+// keyActions are the actions a key can be bound to. Direction actions move
+// the player, or run or attack when the run or attack modifier is held.
+// Actions on an item ask for the item's letter next.
+var keyActions = map[string]Command{
+	"north":      MoveCommand(0, -1),
+	"south":      MoveCommand(0, 1),
+	"west":       MoveCommand(-1, 0),
+	"east":       MoveCommand(1, 0),
+	"northwest":  MoveCommand(-1, -1),
+	"northeast":  MoveCommand(1, -1),
+	"southwest":  MoveCommand(-1, 1),
+	"southeast":  MoveCommand(1, 1),
+	"wait":       {Kind: CommandWait},
+	"open_door":  {Kind: CommandOpenDoor},
+	"close_door": {Kind: CommandCloseDoor},
+	"descend":    {Kind: CommandDescend},
+	"ascend":     {Kind: CommandAscend},
+	"pick_up":    {Kind: CommandPickUp},
+	"drop":       {Kind: CommandDrop},
+	"equip":      {Kind: CommandEquip},
+	"remove":     {Kind: CommandRemove},
+	"inventory":  {Kind: CommandInventory},
+	"use":        {Kind: CommandUse},
+}
+
+// itemPrompts are the questions asked for the letter of the item a command
+// acts on.
+var itemPrompts = map[CommandKind]string{
+	CommandDrop:   "Drop which item?",
+	CommandEquip:  "Equip which item?",
+	CommandRemove: "Take off which item?",
+	CommandUse:    "Quaff or read which item?",
+}
+
+// itemKeys are the keys that pick an inventory item, a for the first.
+var itemKeys = []ebiten.Key{
+	ebiten.KeyA, ebiten.KeyB, ebiten.KeyC, ebiten.KeyD, ebiten.KeyE, ebiten.KeyF, ebiten.KeyG,
+	ebiten.KeyH, ebiten.KeyI, ebiten.KeyJ, ebiten.KeyK, ebiten.KeyL, ebiten.KeyM, ebiten.KeyN,
+	ebiten.KeyO, ebiten.KeyP, ebiten.KeyQ, ebiten.KeyR, ebiten.KeyS, ebiten.KeyT, ebiten.KeyU,
+	ebiten.KeyV, ebiten.KeyW, ebiten.KeyX, ebiten.KeyY, ebiten.KeyZ,
+}
+
+// unbound is the action that removes a binding made by a preset.
+const unbound = "none"
+
+// keyPresets are the sets of movement keys a keybinding file can start from.
+var keyPresets = map[string]map[string]string{
+	"arrows": {
+		"Up": "north", "Down": "south", "Left": "west", "Right": "east",
+	},
+	"numpad": {
+		"Numpad8": "north", "Numpad2": "south", "Numpad4": "west", "Numpad6": "east",
+		"Numpad7": "northwest", "Numpad9": "northeast", "Numpad1": "southwest", "Numpad3": "southeast",
+		"Numpad5": "wait",
+	},
+	"vi": {
+		"K": "north", "J": "south", "H": "west", "L": "east",
+		"Y": "northwest", "U": "northeast", "B": "southwest", "N": "southeast",
+	},
+}
+
+// keyNames are the names keys are given in keybinding files.
+var keyNames = map[string]ebiten.Key{
+	"A": ebiten.KeyA, "B": ebiten.KeyB, "C": ebiten.KeyC, "D": ebiten.KeyD, "E": ebiten.KeyE,
+	"F": ebiten.KeyF, "G": ebiten.KeyG, "H": ebiten.KeyH, "I": ebiten.KeyI, "J": ebiten.KeyJ,
+	"K": ebiten.KeyK, "L": ebiten.KeyL, "M": ebiten.KeyM, "N": ebiten.KeyN, "O": ebiten.KeyO,
+	"P": ebiten.KeyP, "Q": ebiten.KeyQ, "R": ebiten.KeyR, "S": ebiten.KeyS, "T": ebiten.KeyT,
+	"U": ebiten.KeyU, "V": ebiten.KeyV, "W": ebiten.KeyW, "X": ebiten.KeyX, "Y": ebiten.KeyY,
+	"Z": ebiten.KeyZ,
+
+	"0": ebiten.KeyDigit0, "1": ebiten.KeyDigit1, "2": ebiten.KeyDigit2, "3": ebiten.KeyDigit3,
+	"4": ebiten.KeyDigit4, "5": ebiten.KeyDigit5, "6": ebiten.KeyDigit6, "7": ebiten.KeyDigit7,
+	"8": ebiten.KeyDigit8, "9": ebiten.KeyDigit9,
+
+	"Numpad0": ebiten.KeyNumpad0, "Numpad1": ebiten.KeyNumpad1, "Numpad2": ebiten.KeyNumpad2,
+	"Numpad3": ebiten.KeyNumpad3, "Numpad4": ebiten.KeyNumpad4, "Numpad5": ebiten.KeyNumpad5,
+	"Numpad6": ebiten.KeyNumpad6, "Numpad7": ebiten.KeyNumpad7, "Numpad8": ebiten.KeyNumpad8,
+	"Numpad9": ebiten.KeyNumpad9,
+
+	"Up": ebiten.KeyUp, "Down": ebiten.KeyDown, "Left": ebiten.KeyLeft, "Right": ebiten.KeyRight,
+	"Period": ebiten.KeyPeriod, "Comma": ebiten.KeyComma, "Slash": ebiten.KeySlash,
+	"Minus": ebiten.KeyMinus, "Equal": ebiten.KeyEqual, "Space": ebiten.KeySpace,
+	"Enter": ebiten.KeyEnter, "Tab": ebiten.KeyTab, "Backspace": ebiten.KeyBackspace,
+	"Home": ebiten.KeyHome, "End": ebiten.KeyEnd, "PageUp": ebiten.KeyPageUp, "PageDown": ebiten.KeyPageDown,
+	"Shift": ebiten.KeyShift, "Control": ebiten.KeyControl, "Alt": ebiten.KeyAlt,
+}
+
+// keymapFile is the JSON form of a keybinding file. Presets are applied in
+// order, then Keys adds to or overrides them. A key is written as its name,
+// such as "Q" or "Numpad5", with a "Shift+" prefix if Shift must be held.
+type keymapFile struct {
+	Presets []string          `json:"presets"`
+	Run     string            `json:"run"`
+	Attack  string            `json:"attack"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// keyChord is a key, with or without Shift held.
+type keyChord struct {
+	key   ebiten.Key
+	shift bool
+}
+
+// keyBinding maps a key chord to the command it gives.
+type keyBinding struct {
+	chord   keyChord
+	command Command
+}
+
+// Keymap turns key presses into commands.
+type Keymap struct {
+	bindings []keyBinding
+	run      ebiten.Key
+	attack   ebiten.Key
+}
+
+// LoadKeymap reads and checks a keybinding file.
+func LoadKeymap(path string) (*Keymap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	km, err := ParseKeymap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return km, nil
+}
+
+// ParseKeymap builds a keymap from the JSON of a keybinding file. Every
+// problem found is reported, not just the first.
+func ParseKeymap(data []byte) (*Keymap, error) {
+	var file keymapFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	bound := make(map[keyChord]string)
+	bind := func(name string, action string) {
+		chord, err := parseChord(name)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if _, ok := keyActions[action]; !ok && action != unbound {
+			errs = append(errs, fmt.Errorf("key %s: unknown action %q", name, action))
+			return
+		}
+		bound[chord] = action
+	}
+
+	for _, preset := range file.Presets {
+		keys, ok := keyPresets[preset]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown preset %q, expected arrows, numpad or vi", preset))
+			continue
+		}
+		for _, name := range sortedKeys(keys) {
+			bind(name, keys[name])
+		}
+	}
+	for _, name := range sortedKeys(file.Keys) {
+		bind(name, file.Keys[name])
+	}
+
+	km := &Keymap{}
+	// A modifier can't also be bound on its own, though Shift plus the
+	// modifier can be, as with the run key and Shift+Period
+	modifier := func(field string, name string) ebiten.Key {
+		key, ok := keyNames[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown key %q", field, name))
+			return 0
+		}
+		if action := bound[keyChord{key: key}]; action != "" && action != unbound {
+			errs = append(errs, fmt.Errorf("%s: %s is also bound to %s", field, name, action))
+		}
+		return key
+	}
+	km.run = modifier("run", file.Run)
+	km.attack = modifier("attack", file.Attack)
+	if file.Run != "" && file.Run == file.Attack {
+		errs = append(errs, fmt.Errorf("run and attack are both %s", file.Run))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Shifted chords go first so they win over the same key without Shift
+	for chord, action := range bound {
+		if action != unbound {
+			km.bindings = append(km.bindings, keyBinding{chord: chord, command: keyActions[action]})
+		}
+	}
+	sort.Slice(km.bindings, func(i, j int) bool {
+		a, b := km.bindings[i].chord, km.bindings[j].chord
+		if a.shift != b.shift {
+			return a.shift
+		}
+		return a.key < b.key
+	})
+	return km, nil
+}
+
+// parseChord reads a key name with an optional "Shift+" prefix.
+func parseChord(name string) (keyChord, error) {
+	keyName, shift := strings.CutPrefix(name, "Shift+")
+	key, ok := keyNames[keyName]
+	if !ok {
+		return keyChord{}, fmt.Errorf("unknown key %q", name)
+	}
+	return keyChord{key: key, shift: shift}, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Command returns the command for a key chord pressed while run or attack
+// is or isn't held. Diagonal moves are left out unless diagonal is true.
+func (km *Keymap) Command(key ebiten.Key, shift bool, run bool, attack bool, diagonal bool) (Command, bool) {
+	for _, b := range km.bindings {
+		if b.chord.key != key || (b.chord.shift && !shift) {
+			continue
+		}
+		cmd := b.command
+		if cmd.Kind != CommandMove {
+			return cmd, true
+		}
+		if cmd.DX != 0 && cmd.DY != 0 && !diagonal {
+			return Command{}, false
+		}
+		switch {
+		case attack:
+			cmd.Kind = CommandAttack
+		case run:
+			cmd.Kind = CommandRun
+		}
+		return cmd, true
+	}
+	return Command{}, false
+}
+
+// KeyboardCommands reads the player's commands from the keyboard through a
+// keymap. Diagonal turns on the diagonal movement keys. Commands that act
+// on an item wait for the item's letter, asking for it through Prompt.
+type KeyboardCommands struct {
+	Keymap   *Keymap
+	Diagonal bool
+	Prompt   func(message string)
+
+	pending *Command
+}
+
+// NextCommand returns the command for the key just pressed, if any.
+func (k *KeyboardCommands) NextCommand() (Command, bool) {
+	if k.pending != nil {
+		return k.chooseItem()
+	}
+
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	run := ebiten.IsKeyPressed(k.Keymap.run)
+	attack := ebiten.IsKeyPressed(k.Keymap.attack)
+	for _, b := range k.Keymap.bindings {
+		if inpututil.IsKeyJustPressed(b.chord.key) {
+			if cmd, ok := k.Keymap.Command(b.chord.key, shift, run, attack, k.Diagonal); ok {
+				if cmd.Kind.NeedsItem() {
+					k.pending = &cmd
+					k.prompt(itemPrompts[cmd.Kind] + " Press its letter, or Escape to cancel.\n")
+					return Command{}, false
+				}
+				return cmd, true
+			}
+		}
+	}
+	return Command{}, false
+}
+
+// chooseItem waits for the letter of the item the pending command acts on.
+func (k *KeyboardCommands) chooseItem() (Command, bool) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		k.pending = nil
+		k.prompt("Never mind.\n")
+		return Command{}, false
+	}
+	for i, key := range itemKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			cmd := ItemCommand(k.pending.Kind, i)
+			k.pending = nil
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+func (k *KeyboardCommands) prompt(message string) {
+	if k.Prompt != nil {
+		k.Prompt(message)
+	}
+}
+
+// loadKeyboard reads the keymap for a game played from the keyboard.
+func loadKeyboard(g *Game) *KeyboardCommands {
+	km, err := LoadKeymap(KeymapFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return &KeyboardCommands{
+		Keymap:   km,
+		Diagonal: g.GameData.DiagonalMovement,
+		Prompt: func(message string) {
+			g.Systems.UI.AddMessage(message, "info")
+		},
+	}
+}
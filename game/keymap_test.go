@@ -0,0 +1,96 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestDefaultKeymap(t *testing.T) {
+	km, err := LoadKeymap(KeymapFile)
+	if err != nil {
+		t.Fatalf("LoadKeymap returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		key      ebiten.Key
+		shift    bool
+		run      bool
+		attack   bool
+		diagonal bool
+		expected Command
+		ok       bool
+	}{
+		{name: "arrow moves", key: ebiten.KeyUp, expected: MoveCommand(0, -1), ok: true},
+		{name: "vi key moves", key: ebiten.KeyL, expected: MoveCommand(1, 0), ok: true},
+		{name: "numpad moves", key: ebiten.KeyNumpad2, expected: MoveCommand(0, 1), ok: true},
+		{name: "run modifier runs", key: ebiten.KeyLeft, run: true, expected: RunCommand(-1, 0), ok: true},
+		{name: "attack modifier attacks", key: ebiten.KeyH, attack: true, expected: AttackCommand(-1, 0), ok: true},
+		{name: "diagonal needs 8-way movement", key: ebiten.KeyNumpad7},
+		{name: "diagonal with 8-way movement", key: ebiten.KeyY, diagonal: true, expected: MoveCommand(-1, -1), ok: true},
+		{name: "numpad 5 waits", key: ebiten.KeyNumpad5, expected: Command{Kind: CommandWait}, ok: true},
+		{name: "shift period descends", key: ebiten.KeyPeriod, shift: true, expected: Command{Kind: CommandDescend}, ok: true},
+		{name: "period alone does nothing", key: ebiten.KeyPeriod},
+		{name: "shift still moves", key: ebiten.KeyDown, shift: true, expected: MoveCommand(0, 1), ok: true},
+		{name: "unbound key", key: ebiten.KeyZ},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, ok := km.Command(tt.key, tt.shift, tt.run, tt.attack, tt.diagonal)
+			if ok != tt.ok || cmd != tt.expected {
+				t.Errorf("Command() = %+v, %v, expected %+v, %v", cmd, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestKeymapOverridesPresets(t *testing.T) {
+	km, err := ParseKeymap([]byte(`{
+		"presets": ["arrows", "vi"],
+		"run": "Alt",
+		"attack": "Control",
+		"keys": {"W": "north", "K": "none", "Up": "wait"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseKeymap returned error: %v", err)
+	}
+
+	if cmd, ok := km.Command(ebiten.KeyW, false, false, false, false); !ok || cmd != MoveCommand(0, -1) {
+		t.Errorf("W = %+v, %v, expected north", cmd, ok)
+	}
+	if _, ok := km.Command(ebiten.KeyK, false, false, false, false); ok {
+		t.Error("K was unbound but still gives a command")
+	}
+	if cmd, _ := km.Command(ebiten.KeyUp, false, false, false, false); cmd.Kind != CommandWait {
+		t.Errorf("Up = %+v, expected wait", cmd)
+	}
+	if _, ok := km.Command(ebiten.KeyNumpad8, false, false, false, false); ok {
+		t.Error("numpad key bound without the numpad preset")
+	}
+}
+
+func TestParseKeymapErrors(t *testing.T) {
+	_, err := ParseKeymap([]byte(`{
+		"presets": ["arrows", "wasd"],
+		"run": "Up",
+		"attack": "Hyper",
+		"keys": {"Shift+Banana": "wait", "Q": "quaff"}
+	}`))
+	if err == nil {
+		t.Fatal("ParseKeymap expected an error")
+	}
+	for _, want := range []string{`unknown preset "wasd"`, "run: Up is also bound to north", `attack: unknown key "Hyper"`, `unknown key "Shift+Banana"`, `unknown action "quaff"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	if _, err := ParseKeymap([]byte(`{"presets": [], "run": "Period", "attack": "Period", "keys": {}}`)); err == nil {
+		t.Error("ParseKeymap accepted the same key for run and attack")
+	}
+	if _, err := ParseKeymap([]byte(`{"presets": [], "run": "Alt", "attack": "Control", "binds": {}}`)); err == nil {
+		t.Error("ParseKeymap accepted an unknown field")
+	}
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/caustin/rrogue/events"
+)
+
+func TestMonstersLeaveCorpsesAndLoot(t *testing.T) {
+	g := NewHeadlessGame(21, NewScriptedCommands())
+	var dropped *events.LootDroppedEvent
+	g.EventBus.Subscribe(events.LootDroppedEventType, func(e events.Event) { dropped = e.(*events.LootDroppedEvent) })
+
+	var orcs, skeletons int
+	for _, monster := range g.World.QueryMonsters() {
+		pos := *g.World.GetPosition(monster)
+		template := g.World.GetMonster(monster).Template
+		dropped = nil
+		g.EventBus.Publish(events.NewDamageEvent(g.World.ID(monster), 1000, "test", true))
+
+		var corpses int
+		for _, result := range g.World.EntitiesAt(pos.X, pos.Y) {
+			if g.World.GetName(result).Label == "Orc corpse" {
+				corpses++
+			}
+		}
+		switch template {
+		case "orc":
+			orcs++
+			if corpses != 1 {
+				t.Errorf("orc at %v left %d corpses", pos, corpses)
+			}
+			if dropped == nil || !dropped.Corpse || dropped.Gold < 2 || dropped.Source != "Orc" {
+				t.Errorf("orc at %v dropped %+v, expected a corpse and 2d6 gold", pos, dropped)
+			}
+		case "skeleton":
+			skeletons++
+			if corpses != 0 {
+				t.Errorf("skeleton at %v left a corpse", pos)
+			}
+			if dropped != nil && (dropped.Corpse || dropped.Gold != 0) {
+				t.Errorf("skeleton at %v dropped %+v, expected at most its sword", pos, dropped)
+			}
+		}
+	}
+	if orcs == 0 || skeletons == 0 {
+		t.Fatalf("killed %d orcs and %d skeletons, expected some of each", orcs, skeletons)
+	}
+
+	pile := g.World.QueryGold()[0]
+	amount := g.World.GetGold(pile).Amount
+	player := g.World.QueryPlayers()[0]
+	g.World.MoveEntity(player, g.World.GetPosition(pile).X, g.World.GetPosition(pile).Y)
+	g.Systems.Inventory.CollectGold(player)
+	if gold := g.World.GetInventory(player).Gold; gold != amount {
+		t.Errorf("after walking onto %d gold pieces, carrying %d", amount, gold)
+	}
+	if len(g.World.QueryGold()) != orcs-1 {
+		t.Errorf("%d piles of gold left, expected %d", len(g.World.QueryGold()), orcs-1)
+	}
+}
@@ -1,23 +1,94 @@
 package game
 
-import "github.com/caustin/rrogue/level"
+import (
+	"github.com/caustin/rrogue/config"
+	"github.com/caustin/rrogue/level"
+	"github.com/caustin/rrogue/utils"
+	"log"
+)
 
 // GameMap holds all the level and aggregate information for the entire world.
+// The dungeons are stacked one above the other: the stairs down from the
+// bottom of one dungeon lead to the top of the next, and depth keeps counting
+// up across them.
 type GameMap struct {
-	Dungeons     []level.Dungeon
-	CurrentLevel level.Level
+	Dungeons       []level.Dungeon
+	CurrentDungeon int
+	CurrentDepth   int
+	CurrentLevel   level.Level
 }
 
-// NewGameMap creates a new set of maps for the entire game.
-func NewGameMap() GameMap {
-	//Return a new game map of a single level for now
-	l := level.NewLevel()
-	levels := make([]level.Level, 0)
-	levels = append(levels, l)
-	d := level.Dungeon{Name: "default", Levels: levels}
-	dungeons := make([]level.Dungeon, 0)
-	dungeons = append(dungeons, d)
-	gm := GameMap{Dungeons: dungeons, CurrentLevel: l}
+// NewGameMap creates a new set of maps for the entire game, whose tiles are
+// drawn with images.
+func NewGameMap(rng utils.RNG, images *level.TileImages) GameMap {
+	//Return a new game map of the halls, the keep and the bottomless caverns, starting on the first level
+	gm := GameMap{Dungeons: []level.Dungeon{
+		newDungeon("the Halls", level.RoomsLayout, 1, 3, images),
+		newDungeon("the Keep", level.BSPLayout, 4, 3, images),
+		newDungeon("the Caverns", level.CaveLayout, 7, 0, images),
+	}}
+	gm.LevelAt(1, rng)
+	gm.SetCurrentDepth(1)
 	return gm
 
 }
+
+// newDungeon creates a dungeon with no levels yet, laid out by the given kind
+// of generator, drawn with images and using the game's level size and vaults.
+// Its levels start at firstDepth and go depths levels down, or without end if
+// depths is 0.
+func newDungeon(name string, generator level.GeneratorKind, firstDepth int, depths int, images *level.TileImages) level.Dungeon {
+	gd := config.NewGameData()
+	return level.Dungeon{
+		Name:       name,
+		Levels:     make([]level.Level, 0),
+		Width:      gd.LevelWidth,
+		Height:     gd.LevelHeight,
+		FirstDepth: firstDepth,
+		Depths:     depths,
+		Generator:  generator,
+		Vaults:     loadVaults(),
+		Images:     images,
+	}
+}
+
+// loadVaults reads the hand drawn vaults that are stamped into levels.
+func loadVaults() []level.Vault {
+	vaults, err := level.LoadVaults("assets/vaults")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return vaults
+}
+
+// dungeonAt returns the index of the dungeon that holds depth.
+func (gm *GameMap) dungeonAt(depth int) int {
+	for i := range gm.Dungeons {
+		if gm.Dungeons[i].Contains(depth) {
+			return i
+		}
+	}
+	log.Fatalf("no dungeon reaches depth %d", depth)
+	return 0
+}
+
+// LevelAt returns the level at the given depth, in whichever dungeon holds it.
+// Levels are generated the first time they are reached, in which case
+// created is true.
+func (gm *GameMap) LevelAt(depth int, rng utils.RNG) (l level.Level, created bool) {
+	dungeon := &gm.Dungeons[gm.dungeonAt(depth)]
+	for dungeon.FirstDepth+len(dungeon.Levels) <= depth {
+		dungeon.NewLevel(rng)
+		created = true
+	}
+	return dungeon.Levels[depth-dungeon.FirstDepth], created
+}
+
+// SetCurrentDepth makes the already generated level at depth the current one,
+// and its dungeon the current dungeon.
+func (gm *GameMap) SetCurrentDepth(depth int) {
+	gm.CurrentDungeon = gm.dungeonAt(depth)
+	gm.CurrentDepth = depth
+	dungeon := gm.Dungeons[gm.CurrentDungeon]
+	gm.CurrentLevel = dungeon.Levels[depth-dungeon.FirstDepth]
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/caustin/rrogue/assets"
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/level"
+	"github.com/caustin/rrogue/utils"
+	"github.com/caustin/rrogue/world"
+)
+
+// reachable returns every tile that can be walked to from start, treating
+// doors as open.
+func reachable(l level.Level, start components.Position) map[components.Position]bool {
+	seen := map[components.Position]bool{start: true}
+	queue := []components.Position{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range []components.Position{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}} {
+			n := components.Position{X: p.X + d.X, Y: p.Y + d.Y}
+			if !l.InBounds(n.X, n.Y) || seen[n] || l.Tiles[l.GetIndexFromXY(n.X, n.Y)].TileType == level.WALL {
+				continue
+			}
+			seen[n] = true
+			queue = append(queue, n)
+		}
+	}
+	return seen
+}
+
+// checkPlacement fails the test unless the player stands on floor from which
+// every monster and the level's stairs can be reached.
+func checkPlacement(t *testing.T, w world.WorldService, l level.Level) {
+	t.Helper()
+	player := *w.GetPosition(w.QueryPlayers()[0])
+	if tile := l.Tiles[l.GetIndexFromXY(player.X, player.Y)]; tile.TileType == level.WALL {
+		t.Fatalf("depth %d: player placed in the rock at %v", l.Depth, player)
+	}
+	paths := reachable(l, player)
+	if !paths[l.StairsDown] {
+		t.Errorf("depth %d: stairs down at %v can't be reached from %v", l.Depth, l.StairsDown, player)
+	}
+	if l.Depth > 1 && !paths[l.StairsUp] {
+		t.Errorf("depth %d: stairs up at %v can't be reached from %v", l.Depth, l.StairsUp, player)
+	}
+	monsters := 0
+	for _, monster := range w.QueryMonsters() {
+		if pos := *w.GetPosition(monster); !paths[pos] {
+			t.Errorf("depth %d: monster at %v can't be reached from %v", l.Depth, pos, player)
+		}
+		monsters++
+	}
+	if monsters == 0 {
+		t.Errorf("depth %d: no monsters were placed", l.Depth)
+	}
+}
+
+func TestGameStartsOnACaveLevel(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		rng := utils.NewSeededRNG(seed)
+		caverns := newDungeon("the Caverns", level.CaveLayout, 1, 0, loadTileImages(assets.Null{}))
+		l := caverns.NewLevel(rng)
+		w := world.NewGameWorld(l, rng, loadTemplates(), assets.Null{})
+		checkPlacement(t, w, l)
+	}
+}
+
+func TestDescendingIntoTheCaverns(t *testing.T) {
+	g := NewHeadlessGame(4, NewScriptedCommands())
+	player := g.World.QueryPlayers()[0]
+	caverns := g.Map.dungeonAt(7)
+	if g.Map.Dungeons[caverns].Generator != level.CaveLayout {
+		t.Fatalf("depth 7 is in %s, laid out by %q, expected the caverns", g.Map.Dungeons[caverns].Name, g.Map.Dungeons[caverns].Generator)
+	}
+
+	for g.Map.CurrentDungeon != caverns {
+		stairs := g.Map.CurrentLevel.StairsDown
+		g.World.MoveEntity(player, stairs.X, stairs.Y)
+		if !UseStairs(g, Descend) {
+			t.Fatalf("couldn't descend from depth %d", g.Map.CurrentDepth)
+		}
+	}
+	if g.Map.CurrentDepth != 7 {
+		t.Fatalf("reached the caverns at depth %d, expected 7", g.Map.CurrentDepth)
+	}
+	checkPlacement(t, g.World, g.Map.CurrentLevel)
+}
@@ -6,8 +6,14 @@ import (
 	"github.com/norendren/go-fov/fov"
 )
 
+// fleeHealthFraction is how far a monster's health has to drop, as a
+// fraction of its maximum, before it runs from the player instead of
+// fighting.
+const fleeHealthFraction = 4
+
 func UpdateMonster(game *Game) {
 	l := game.Map.CurrentLevel
+	diagonal := game.GameData.DiagonalMovement
 	playerPosition := components.Position{}
 
 	for _, plr := range game.World.QueryPlayers() {
@@ -15,34 +21,59 @@ func UpdateMonster(game *Game) {
 		playerPosition.X = pos.X
 		playerPosition.Y = pos.Y
 	}
+
+	//Every monster shares the same maps this turn.  The flee map is only
+	//built once a monster needs it.
+	toPlayer := level.NewDijkstraMap(l, []components.Position{playerPosition}, diagonal)
+	var fromPlayer *level.DijkstraMap
+
 	for _, result := range game.World.QueryMonsters() {
 		pos := game.World.GetPosition(result)
+		health := game.World.GetHealth(result)
 		//mon := result.Components[monster].(*Monster)
 
 		monsterSees := fov.New()
 		monsterSees.Compute(l, pos.X, pos.Y, 8)
-		if monsterSees.IsVisible(playerPosition.X, playerPosition.Y) {
+		if !monsterSees.IsVisible(playerPosition.X, playerPosition.Y) {
+			continue
+		}
 
-			if pos.GetManhattanDistance(&playerPosition) == 1 {
-				//The monster is right next to the player.  Just smack him down
-				game.Systems.Combat.ProcessAttack(pos, &playerPosition)
+		fleeing := health.CurrentHealth*fleeHealthFraction < health.MaxHealth
+		inRange := l.InMeleeRange(pos, &playerPosition, diagonal)
+		if inRange && !fleeing {
+			//The monster is right next to the player.  Just smack him down
+			game.Systems.Combat.ProcessAttack(pos, &playerPosition)
+			continue
+		}
 
-			} else {
-				astar := level.AStar{}
-				path := astar.GetPath(l, pos, &playerPosition)
-				if len(path) > 1 {
-					nextTile := l.Tiles[l.GetIndexFromXY(path[1].X, path[1].Y)]
-					if !nextTile.Blocked {
-						l.Tiles[l.GetIndexFromXY(pos.X, pos.Y)].Blocked = false
-						pos.X = path[1].X
-						pos.Y = path[1].Y
-						nextTile.Blocked = true
-					}
-				}
+		field := toPlayer
+		if fleeing {
+			if fromPlayer == nil {
+				f := toPlayer.Flee(l)
+				fromPlayer = &f
 			}
+			field = *fromPlayer
+		}
 
+		next, ok := field.Downhill(l, pos.X, pos.Y)
+		if !ok {
+			if inRange {
+				//Cornered, so it fights
+				game.Systems.Combat.ProcessAttack(pos, &playerPosition)
+			}
+			continue
 		}
 
+		nextTile := l.Tiles[l.GetIndexFromXY(next.X, next.Y)]
+		if nextTile.TileType == level.DOOR && nextTile.DoorState == level.DoorClosed {
+			//Opening the door takes the monster's turn
+			l.OpenDoor(next.X, next.Y)
+			l.PlayerVisible.Compute(l, playerPosition.X, playerPosition.Y, 8)
+		} else {
+			l.Tiles[l.GetIndexFromXY(pos.X, pos.Y)].Blocked = false
+			game.World.MoveEntity(result, next.X, next.Y)
+			nextTile.Blocked = true
+		}
 	}
 
 }
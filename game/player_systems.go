@@ -4,9 +4,6 @@ import (
 	"github.com/caustin/rrogue/components"
 	level2 "github.com/caustin/rrogue/level"
 	"time"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 // AutoMoveState tracks the state of auto-movement for smooth progression
@@ -18,9 +15,9 @@ type AutoMoveState struct {
 	StopRequested bool
 }
 
+// TakePlayerAction carries out the player's next command from g.Input,
+// returning true if it took a turn.
 func TakePlayerAction(g *Game) bool {
-	turnTaken := false
-
 	// Initialize auto-move state if needed
 	if g.AutoMoveState == nil {
 		g.AutoMoveState = &AutoMoveState{
@@ -33,55 +30,34 @@ func TakePlayerAction(g *Game) bool {
 		return processAutoMovement(g)
 	}
 
-	x := 0
-	y := 0
-
-	// Check for modifier key (period) + direction for auto-movement
-	isAutoMove := ebiten.IsKeyPressed(ebiten.KeyPeriod)
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
-		y = -1
-		if isAutoMove {
-			return startAutoMovement(g, 0, -1)
-		}
-	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
-		y = 1
-		if isAutoMove {
-			return startAutoMovement(g, 0, 1)
-		}
-	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
-		x = -1
-		if isAutoMove {
-			return startAutoMovement(g, -1, 0)
-		}
-	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
-		x = 1
-		if isAutoMove {
-			return startAutoMovement(g, 1, 0)
-		}
-	}
-
-	// Any key press stops auto-movement
-	if g.AutoMoveState.Active && (inpututil.IsKeyJustPressed(ebiten.KeyUp) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyDown) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyLeft) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyRight) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyQ) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyEscape)) {
-		g.AutoMoveState.Active = false
+	cmd, ok := g.Input.NextCommand()
+	if !ok {
 		return false
 	}
+	if g.Recording != nil {
+		g.Recording.Record(g.TurnCounter, cmd)
+	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
-		turnTaken = true
+	switch cmd.Kind {
+	case CommandDescend:
+		return UseStairs(g, Descend)
+	case CommandAscend:
+		return UseStairs(g, Ascend)
+	case CommandRun:
+		return startAutoMovement(g, cmd.DX, cmd.DY)
+	case CommandAttack:
+		return attackAdjacent(g, cmd.DX, cmd.DY)
+	case CommandWait:
+		return true
+	case CommandOpenDoor:
+		return OpenAdjacentDoor(g)
+	case CommandCloseDoor:
+		return CloseAdjacentDoor(g)
+	case CommandPickUp, CommandDrop, CommandEquip, CommandRemove, CommandInventory, CommandUse:
+		return UseInventory(g, cmd)
 	}
 
+	x, y := cmd.DX, cmd.DY
 	level := g.Map.CurrentLevel
 
 	for _, result := range g.World.QueryPlayers() {
@@ -89,12 +65,21 @@ func TakePlayerAction(g *Game) bool {
 		index := level.GetIndexFromXY(pos.X+x, pos.Y+y)
 
 		tile := level.Tiles[index]
-		if tile.Blocked != true {
+		if x != 0 && y != 0 && !level.CanStep(pos.X, pos.Y, x, y) {
+			//Diagonal steps can't cut corners or go through doorways
+			continue
+
+		} else if tile.TileType == level2.DOOR && tile.DoorState != level2.DoorOpen {
+			//Walking into a closed door opens it, unless it is locked and there's no key
+			return OpenDoor(g, pos.X+x, pos.Y+y)
+
+		} else if tile.Blocked != true {
 			level.Tiles[level.GetIndexFromXY(pos.X, pos.Y)].Blocked = false
-			pos.X += x
-			pos.Y += y
+			g.World.MoveEntity(result, pos.X+x, pos.Y+y)
 			level.Tiles[index].Blocked = true
 			level.PlayerVisible.Compute(level, pos.X, pos.Y, 8)
+			pickUpKeys(g, pos)
+			g.Systems.Inventory.CollectGold(result)
 
 		} else if x != 0 || y != 0 {
 			if level.Tiles[index].TileType != level2.WALL {
@@ -107,7 +92,25 @@ func TakePlayerAction(g *Game) bool {
 
 	}
 
-	if x != 0 || y != 0 || turnTaken {
+	return x != 0 || y != 0
+}
+
+// attackAdjacent attacks whoever is at dx, dy from the player. Attacking
+// an empty tile, or one out of reach around a corner or through a doorway,
+// doesn't take a turn.
+func attackAdjacent(g *Game, dx, dy int) bool {
+	for _, result := range g.World.QueryPlayers() {
+		pos := g.World.GetPosition(result)
+		if _, ok := g.World.EntityAt(pos.X+dx, pos.Y+dy); !ok {
+			g.Systems.UI.AddMessage("There is nothing there to attack.\n", "info")
+			return false
+		}
+		target := components.Position{X: pos.X + dx, Y: pos.Y + dy}
+		if !g.Map.CurrentLevel.InMeleeRange(pos, &target, g.GameData.DiagonalMovement) {
+			g.Systems.UI.AddMessage("You can't reach that from here.\n", "info")
+			return false
+		}
+		g.Systems.Combat.ProcessAttack(pos, &target)
 		return true
 	}
 	return false
@@ -146,8 +149,9 @@ func processAutoMovement(g *Game) bool {
 		nextY := pos.Y + dy
 		nextIndex := level.GetIndexFromXY(nextX, nextY)
 
-		// Stop if we can't move (blocked by wall)
-		if level.Tiles[nextIndex].TileType == level2.WALL {
+		// Stop if we can't move (blocked by wall, a closed door or a corner)
+		nextTile := level.Tiles[nextIndex]
+		if !level.CanStep(pos.X, pos.Y, dx, dy) || (nextTile.TileType == level2.DOOR && nextTile.DoorState != level2.DoorOpen) {
 			g.AutoMoveState.Active = false
 			return false
 		}
@@ -189,13 +193,20 @@ func executePlayerMove(g *Game, dx, dy int) bool {
 		index := level.GetIndexFromXY(pos.X+dx, pos.Y+dy)
 
 		tile := level.Tiles[index]
-		if !tile.Blocked {
+		if dx != 0 && dy != 0 && !level.CanStep(pos.X, pos.Y, dx, dy) {
+			// Diagonal steps can't cut corners or go through doorways
+			return false
+		} else if tile.TileType == level2.DOOR && tile.DoorState != level2.DoorOpen {
+			// Open door
+			return OpenDoor(g, pos.X+dx, pos.Y+dy)
+		} else if !tile.Blocked {
 			// Move player
 			level.Tiles[level.GetIndexFromXY(pos.X, pos.Y)].Blocked = false
-			pos.X += dx
-			pos.Y += dy
+			g.World.MoveEntity(result, pos.X+dx, pos.Y+dy)
 			level.Tiles[index].Blocked = true
 			level.PlayerVisible.Compute(level, pos.X, pos.Y, 8)
+			pickUpKeys(g, pos)
+			g.Systems.Inventory.CollectGold(result)
 			return true
 		} else if tile.TileType != level2.WALL {
 			// Attack monster
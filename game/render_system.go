@@ -11,12 +11,10 @@ func ProcessRenderables(g *Game, level level.Level, screen *ebiten.Image) {
 		pos := g.World.GetPosition(result)
 		img := g.World.GetRenderable(result).Image
 
-		if level.PlayerVisible.IsVisible(pos.X, pos.Y) {
-			index := level.GetIndexFromXY(pos.X, pos.Y)
-			tile := level.Tiles[index]
+		if level.PlayerVisible.IsVisible(pos.X, pos.Y) && g.Camera.Contains(pos.X, pos.Y) {
 			op := utils.GetDrawOptions()
 
-			op.GeoM.Translate(float64(tile.PixelX), float64(tile.PixelY))
+			op.GeoM.Translate(g.Camera.ToScreen(pos.X, pos.Y, g.GameData))
 			screen.DrawImage(img, op)
 			utils.PutDrawOptions(op)
 		}
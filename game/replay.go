@@ -0,0 +1,182 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ReplayVersion is the version of the replay format. Like SaveVersion, it
+// must be bumped whenever a change to the game would make old replays play
+// out differently.
+const ReplayVersion = 12
+
+// ErrReplayVersion is returned when loading a replay from another version
+// of the game.
+var ErrReplayVersion = errors.New("replay is from a different version of the game")
+
+// DefaultReplayDelay is the time between commands when a replay is played
+// back at normal speed.
+const DefaultReplayDelay = 250 * time.Millisecond
+
+// ReplayEntry is one command the player gave and the turn it was given on.
+type ReplayEntry struct {
+	Turn    int     `json:"turn"`
+	Command Command `json:"command"`
+}
+
+// Replay is a record of a run: the seed it started from and every command
+// the player gave. Every other random decision is drawn from the seed, so
+// playing the commands back against a new game from the same seed
+// reproduces the run exactly.
+type Replay struct {
+	Version  int           `json:"version"`
+	Seed     int64         `json:"seed"`
+	Commands []ReplayEntry `json:"commands"`
+}
+
+// NewReplay starts an empty replay of a run from seed.
+func NewReplay(seed int64) *Replay {
+	return &Replay{Version: ReplayVersion, Seed: seed}
+}
+
+// Record adds a command the player gave on turn.
+func (r *Replay) Record(turn int, cmd Command) {
+	r.Commands = append(r.Commands, ReplayEntry{Turn: turn, Command: cmd})
+}
+
+// Save writes the replay to path as JSON.
+func (r *Replay) Save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadReplay reads a replay written by Save. A replay from another version
+// of the game fails with ErrReplayVersion.
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if r.Version != ReplayVersion {
+		return nil, fmt.Errorf("%s: %w: version %d, expected %d", path, ErrReplayVersion, r.Version, ReplayVersion)
+	}
+	return &r, nil
+}
+
+// replayDelays are the speeds a replay can be played at, slowest first.
+// Zero plays it as fast as the game runs.
+var replayDelays = []time.Duration{
+	time.Second, 500 * time.Millisecond, DefaultReplayDelay, 100 * time.Millisecond, 50 * time.Millisecond, 0,
+}
+
+// ReplayPlayer plays a replay's commands back in order, one every Delay.
+// It checks each command comes on the turn it was recorded on; if not, the
+// game has played out differently and the rest of the replay can't be
+// trusted.
+type ReplayPlayer struct {
+	Delay  time.Duration
+	Paused bool
+
+	replay   *Replay
+	next     int
+	turn     *int
+	last     time.Time
+	desynced bool
+}
+
+// NewReplayPlayer plays replay back against the game whose turn counter
+// turn points to.
+func NewReplayPlayer(replay *Replay, turn *int) *ReplayPlayer {
+	return &ReplayPlayer{Delay: DefaultReplayDelay, replay: replay, turn: turn}
+}
+
+// NewReplayGame starts a game from the replay's seed and plays the replay
+// back in it. Replays are watched, not saved or recorded.
+func NewReplayGame(replay *Replay) *Game {
+	g := NewGameWithSeed(replay.Seed)
+	g.Playback = NewReplayPlayer(replay, &g.TurnCounter)
+	g.Input = g.Playback
+	g.Recording = nil
+	g.Systems.UI.AddMessage("Playing replay. +/- change speed, space pauses.\n", "info")
+	return g
+}
+
+// NextCommand returns the next command once Delay has passed since the
+// last one.
+func (p *ReplayPlayer) NextCommand() (Command, bool) {
+	if p.Paused || p.Done() || time.Since(p.last) < p.Delay {
+		return Command{}, false
+	}
+	entry := p.replay.Commands[p.next]
+	p.next++
+	p.last = time.Now()
+
+	if entry.Turn != *p.turn && !p.desynced {
+		p.desynced = true
+		log.Printf("Replay out of sync: command %d was recorded on turn %d but played on turn %d", p.next, entry.Turn, *p.turn)
+	}
+	return entry.Command, true
+}
+
+// Done reports whether every command has been played.
+func (p *ReplayPlayer) Done() bool {
+	return p.next >= len(p.replay.Commands)
+}
+
+// Desynced reports whether the game has stopped following the replay.
+func (p *ReplayPlayer) Desynced() bool {
+	return p.desynced
+}
+
+// Faster steps the playback speed up.
+func (p *ReplayPlayer) Faster() {
+	p.stepSpeed(1)
+}
+
+// Slower steps the playback speed down.
+func (p *ReplayPlayer) Slower() {
+	p.stepSpeed(-1)
+}
+
+func (p *ReplayPlayer) stepSpeed(step int) {
+	i := 0
+	for i < len(replayDelays)-1 && replayDelays[i] > p.Delay {
+		i++
+	}
+	i += step
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(replayDelays) {
+		i = len(replayDelays) - 1
+	}
+	p.Delay = replayDelays[i]
+}
+
+// HandleControls reads the playback keys: '+' speeds up, '-' slows down
+// and space pauses.
+func (p *ReplayPlayer) HandleControls() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		p.Faster()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		p.Slower()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		p.Paused = !p.Paused
+	}
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReplayPlaysBackTheRun(t *testing.T) {
+	commands := []Command{
+		MoveCommand(0, 1), RunCommand(1, 0), {Kind: CommandWait}, AttackCommand(0, -1),
+		MoveCommand(-1, 0), {Kind: CommandOpenDoor}, MoveCommand(0, -1), {Kind: CommandDescend},
+	}
+	recorded := playScript(t, 11, commands...)
+	if len(recorded.Recording.Commands) != len(commands) {
+		t.Fatalf("recorded %d commands, expected %d", len(recorded.Recording.Commands), len(commands))
+	}
+
+	// Go through a file, as a replay attached to a bug report would
+	path := filepath.Join(t.TempDir(), "run.replay")
+	if err := recorded.Recording.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	replay, err := LoadReplay(path)
+	if err != nil {
+		t.Fatalf("LoadReplay returned error: %v", err)
+	}
+	if !reflect.DeepEqual(replay, recorded.Recording) {
+		t.Fatalf("loaded replay %+v, expected %+v", replay, recorded.Recording)
+	}
+
+	g := NewHeadlessGame(replay.Seed, nil)
+	player := NewReplayPlayer(replay, &g.TurnCounter)
+	player.Delay = 0
+	g.Input = player
+	for steps := 0; (!player.Done() || g.Turn != WaitingForPlayerInput) && g.Turn != GameOver; steps++ {
+		if steps > 10*len(commands) {
+			t.Fatalf("replay still running after %d steps", steps)
+		}
+		g.Step()
+	}
+
+	if player.Desynced() {
+		t.Error("replay went out of sync")
+	}
+	if g.TurnCounter != recorded.TurnCounter || g.Map.CurrentDepth != recorded.Map.CurrentDepth {
+		t.Errorf("replay ended on turn %d at depth %d, expected turn %d at depth %d",
+			g.TurnCounter, g.Map.CurrentDepth, recorded.TurnCounter, recorded.Map.CurrentDepth)
+	}
+	if !reflect.DeepEqual(g.World.Snapshot(), recorded.World.Snapshot()) || g.RNG.State() != recorded.RNG.State() {
+		t.Error("replay played out differently from the recorded run")
+	}
+}
+
+func TestLoadReplayRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.replay")
+	if err := os.WriteFile(path, []byte(`{"version": 0, "seed": 1, "commands": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplay(path); !errors.Is(err, ErrReplayVersion) {
+		t.Errorf("LoadReplay error = %v, expected ErrReplayVersion", err)
+	}
+
+	dance := fmt.Sprintf(`{"version": %d, "seed": 1, "commands": [{"turn": 0, "command": {"kind": "dance"}}]}`, ReplayVersion)
+	if err := os.WriteFile(path, []byte(dance), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplay(path); err == nil || errors.Is(err, ErrReplayVersion) {
+		t.Errorf("LoadReplay error = %v, expected an unknown command", err)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	p := NewReplayPlayer(NewReplay(1), new(int))
+	p.Slower()
+	p.Slower()
+	p.Slower()
+	if p.Delay != replayDelays[0] {
+		t.Errorf("slowest delay = %v, expected %v", p.Delay, replayDelays[0])
+	}
+	for i := 0; i < len(replayDelays)+1; i++ {
+		p.Faster()
+	}
+	if p.Delay != 0 {
+		t.Errorf("fastest delay = %v, expected 0", p.Delay)
+	}
+}
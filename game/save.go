@@ -0,0 +1,215 @@
+package game
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/caustin/rrogue/assets"
+	"github.com/caustin/rrogue/config"
+	"github.com/caustin/rrogue/level"
+	"github.com/caustin/rrogue/systems"
+	"github.com/caustin/rrogue/utils"
+	"github.com/caustin/rrogue/world"
+)
+
+// SaveVersion is the version of the save format. It must be bumped whenever
+// a change to the game would stop an older save from loading correctly.
+const SaveVersion = 8
+
+// ErrSaveVersion is returned when loading a save from another version of
+// the game.
+var ErrSaveVersion = errors.New("save file is from a different version of the game")
+
+// ErrQuit is returned from Update once the game has been saved and the
+// window can close.
+var ErrQuit = errors.New("quit")
+
+// saveFile is everything needed to resume a game. Levels and entities keep
+// their own state; the RNG is saved as its seed and position in the stream
+// so the rest of the run plays out as it would have.
+type saveFile struct {
+	Version        int                    `json:"version"`
+	Seed           int64                  `json:"seed"`
+	RNGState       uint64                 `json:"rng_state"`
+	Turn           TurnState              `json:"turn"`
+	TurnCounter    int                    `json:"turn_counter"`
+	CurrentDungeon int                    `json:"current_dungeon"`
+	CurrentDepth   int                    `json:"current_depth"`
+	Dungeons       []level.Dungeon        `json:"dungeons"`
+	Entities       []world.EntitySnapshot `json:"entities"`
+	Knowledge      world.ItemKnowledge    `json:"knowledge"`
+	Messages       []systems.UIMessage    `json:"messages"`
+	Replay         *Replay                `json:"replay,omitempty"`
+}
+
+// Save writes the game to path. The file is written next to path first and
+// then moved into place, so a failed save never leaves half a file behind.
+func (g *Game) Save(path string) error {
+	save := saveFile{
+		Version:        SaveVersion,
+		Seed:           g.Seed,
+		RNGState:       g.RNG.State(),
+		Turn:           g.Turn,
+		TurnCounter:    g.TurnCounter,
+		CurrentDungeon: g.Map.CurrentDungeon,
+		CurrentDepth:   g.Map.CurrentDepth,
+		Dungeons:       g.Map.Dungeons,
+		Entities:       g.World.Snapshot(),
+		Knowledge:      g.World.Knowledge(),
+		Messages:       g.Systems.UI.GetCurrentMessages(),
+		Replay:         g.Recording,
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := writeSave(f, save); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadGame resumes the game saved at path. A save from another version of
+// the game fails with ErrSaveVersion.
+func LoadGame(path string) (*Game, error) {
+	g, err := loadGame(path, assets.Files{})
+	if err != nil {
+		return nil, err
+	}
+	g.Input = loadKeyboard(g)
+	return g, nil
+}
+
+// loadGame resumes the game saved at path, loading images through provider.
+func loadGame(path string, provider assets.Provider) (*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	save, err := readSave(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if save.CurrentDungeon < 0 || save.CurrentDungeon >= len(save.Dungeons) {
+		return nil, fmt.Errorf("%s: current dungeon %d does not exist", path, save.CurrentDungeon)
+	}
+	if d := save.Dungeons[save.CurrentDungeon]; save.CurrentDepth < d.FirstDepth || save.CurrentDepth >= d.FirstDepth+len(d.Levels) {
+		return nil, fmt.Errorf("%s: current depth %d does not exist", path, save.CurrentDepth)
+	}
+	for _, d := range save.Dungeons {
+		if _, err := level.NewGenerator(d.Generator); err != nil {
+			return nil, fmt.Errorf("%s: dungeon %s: %w", path, d.Name, err)
+		}
+	}
+
+	g := &Game{}
+	g.Assets = provider
+	g.Seed = save.Seed
+	g.Recording = save.Replay
+	g.RNG = utils.NewSeededRNG(save.Seed)
+	g.RNG.SetState(save.RNGState)
+	g.GameData = config.NewGameData()
+	g.Camera = level.NewCamera(g.GameData.ScreenWidth, g.GameData.ScreenHeight-g.GameData.UIHeight)
+
+	// Vaults and tile images aren't saved, so the dungeons get the game's back
+	images := loadTileImages(provider)
+	for _, d := range save.Dungeons {
+		d.Vaults = loadVaults()
+		d.Images = images
+		for i := range d.Levels {
+			d.Levels[i].Restore(images)
+		}
+		g.Map.Dungeons = append(g.Map.Dungeons, d)
+	}
+	g.Map.CurrentDungeon = save.CurrentDungeon
+	g.Map.SetCurrentDepth(save.CurrentDepth)
+
+	g.World = world.RestoreGameWorld(loadTemplates(), g.Assets, save.CurrentDepth, save.Knowledge, save.Entities)
+	g.wireSystems()
+
+	g.Systems.GameState.SetTurnCounter(save.TurnCounter)
+	g.Systems.GameState.ChangeTurn(systems.TurnState(save.Turn))
+	g.Systems.UI.RestoreMessages(save.Messages)
+
+	l := g.Map.CurrentLevel
+	for _, player := range g.World.QueryPlayers() {
+		pos := g.World.GetPosition(player)
+		l.PlayerVisible.Compute(l, pos.X, pos.Y, 8)
+	}
+	return g, nil
+}
+
+// quit writes the replay and saves the game, or deletes the save once the
+// game is over so a finished run can't be resumed, and tells ebiten to stop.
+func (g *Game) quit() error {
+	if g.Recording != nil && g.ReplayPath != "" {
+		if err := g.Recording.Save(g.ReplayPath); err != nil {
+			return fmt.Errorf("saving replay: %w", err)
+		}
+	}
+	if g.SavePath == "" {
+		return ErrQuit
+	}
+	if g.Turn == GameOver {
+		if err := os.Remove(g.SavePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return ErrQuit
+	}
+	if err := g.Save(g.SavePath); err != nil {
+		return fmt.Errorf("saving game: %w", err)
+	}
+	return ErrQuit
+}
+
+// writeSave writes a save as gzipped JSON.
+func writeSave(w io.Writer, save saveFile) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(save); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// readSave reads a save written by writeSave. The version is checked before
+// anything else is decoded, since the rest of an older save may not fit.
+func readSave(r io.Reader) (saveFile, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return saveFile{}, fmt.Errorf("not a save file: %w", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return saveFile{}, err
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return saveFile{}, fmt.Errorf("not a save file: %w", err)
+	}
+	if header.Version != SaveVersion {
+		return saveFile{}, fmt.Errorf("%w: version %d, expected %d", ErrSaveVersion, header.Version, SaveVersion)
+	}
+
+	var save saveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return saveFile{}, err
+	}
+	return save, nil
+}
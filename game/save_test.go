@@ -0,0 +1,164 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/caustin/rrogue/assets"
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/level"
+	"github.com/caustin/rrogue/systems"
+	"github.com/caustin/rrogue/world"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	save := saveFile{
+		Version:        SaveVersion,
+		Seed:           42,
+		RNGState:       1234567890123,
+		Turn:           ProcessingMonsterTurn,
+		TurnCounter:    17,
+		CurrentDungeon: 0,
+		CurrentDepth:   1,
+		Dungeons: []level.Dungeon{{
+			Name:       "the Keep",
+			Width:      2,
+			Height:     1,
+			FirstDepth: 1,
+			Generator:  level.BSPLayout,
+			Levels: []level.Level{{
+				Width:  2,
+				Height: 1,
+				Depth:  1,
+				Tiles: []*level.MapTile{
+					{TileType: level.FLOOR, IsRevealed: true},
+					{TileType: level.DOOR, DoorState: level.DoorLocked, Blocked: true},
+				},
+				StairsDown: components.Position{X: 1, Y: 0},
+			}},
+		}},
+		Entities: []world.EntitySnapshot{{
+			Player:     true,
+			Sprite:     "assets/player.png",
+			Position:   &components.Position{X: 0, Y: 0},
+			Health:     &components.Health{MaxHealth: 30, CurrentHealth: 12},
+			Keyring:    &components.Keyring{Keys: 1},
+			Experience: &components.Experience{Level: 3, XP: 75, ToHitBonus: 2},
+		}, {
+			Monster:  &components.Monster{Template: "orc"},
+			Sprite:   "assets/orc.png",
+			Position: &components.Position{X: 1, Y: 0},
+		}, {
+			Corpse:   true,
+			Sprite:   "assets/corpse.png",
+			Position: &components.Position{X: 1, Y: 0},
+		}},
+		Knowledge: world.ItemKnowledge{
+			Appearances: map[string]string{"healing_potion": "Murky Potion", "fire_scroll": "Scroll labeled THARR"},
+			Identified:  map[string]bool{"healing_potion": true},
+		},
+		Messages: []systems.UIMessage{{Text: "Seed: 42\n", MessageType: "info"}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSave(&buf, save); err != nil {
+		t.Fatalf("writeSave returned error: %v", err)
+	}
+	loaded, err := readSave(&buf)
+	if err != nil {
+		t.Fatalf("readSave returned error: %v", err)
+	}
+
+	if loaded.RNGState != save.RNGState || loaded.Turn != save.Turn || loaded.TurnCounter != save.TurnCounter {
+		t.Errorf("loaded state = %d, %d, %d, expected %d, %d, %d",
+			loaded.RNGState, loaded.Turn, loaded.TurnCounter, save.RNGState, save.Turn, save.TurnCounter)
+	}
+	if d := loaded.Dungeons[0]; d.Generator != level.BSPLayout || d.FirstDepth != 1 {
+		t.Errorf("dungeon = %s laid out by %q from depth %d", d.Name, d.Generator, d.FirstDepth)
+	}
+	door := loaded.Dungeons[0].Levels[0].Tiles[1]
+	if door.TileType != level.DOOR || door.DoorState != level.DoorLocked || !door.Blocked {
+		t.Errorf("door tile = %+v", door)
+	}
+	if !loaded.Dungeons[0].Levels[0].Tiles[0].IsRevealed {
+		t.Error("revealed tile was not revealed after loading")
+	}
+	player := loaded.Entities[0]
+	if !player.Player || player.Health.CurrentHealth != 12 || player.Keyring.Keys != 1 || player.Armor != nil {
+		t.Errorf("player = %+v", player)
+	}
+	if xp := player.Experience; xp == nil || xp.Level != 3 || xp.XP != 75 || xp.ToHitBonus != 2 {
+		t.Errorf("experience = %+v", xp)
+	}
+	if monster := loaded.Entities[1].Monster; monster == nil || monster.Template != "orc" {
+		t.Errorf("monster = %+v, expected an orc", monster)
+	}
+	if !loaded.Entities[2].Corpse {
+		t.Error("corpse was not a corpse after loading")
+	}
+	if k := loaded.Knowledge; k.Appearances["fire_scroll"] != "Scroll labeled THARR" || !k.Identified["healing_potion"] || k.Identified["fire_scroll"] {
+		t.Errorf("knowledge = %+v", k)
+	}
+	if len(loaded.Messages) != 1 || loaded.Messages[0].Text != "Seed: 42\n" {
+		t.Errorf("messages = %+v", loaded.Messages)
+	}
+}
+
+func TestLoadGameKeepsEachDungeonsGenerator(t *testing.T) {
+	g := NewHeadlessGame(3, NewScriptedCommands())
+	path := filepath.Join(t.TempDir(), "game.sav")
+	if err := g.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	loaded, err := loadGame(path, assets.Null{})
+	if err != nil {
+		t.Fatalf("loadGame returned error: %v", err)
+	}
+
+	if len(loaded.Map.Dungeons) != len(g.Map.Dungeons) {
+		t.Fatalf("loaded %d dungeons, expected %d", len(loaded.Map.Dungeons), len(g.Map.Dungeons))
+	}
+	for i, d := range g.Map.Dungeons {
+		if generator := loaded.Map.Dungeons[i].Generator; generator != d.Generator {
+			t.Errorf("%s is laid out by %q after loading, expected %q", d.Name, generator, d.Generator)
+		}
+	}
+
+	// The keep's first level is laid out by the BSP generator in both games
+	keep := g.Map.Dungeons[g.Map.dungeonAt(4)]
+	if keep.Generator != level.BSPLayout {
+		t.Fatalf("depth 4 is in %s, laid out by %q, expected a BSP dungeon", keep.Name, keep.Generator)
+	}
+	want, _ := g.Map.LevelAt(4, g.RNG)
+	got, _ := loaded.Map.LevelAt(4, loaded.RNG)
+	if !reflect.DeepEqual(got.Rooms, want.Rooms) {
+		t.Errorf("depth 4 has rooms %v after loading, expected %v", got.Rooms, want.Rooms)
+	}
+}
+
+func TestReadSaveRejectsOtherVersions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSave(&buf, saveFile{Version: SaveVersion + 1}); err != nil {
+		t.Fatalf("writeSave returned error: %v", err)
+	}
+	if _, err := readSave(&buf); !errors.Is(err, ErrSaveVersion) {
+		t.Errorf("readSave error = %v, expected ErrSaveVersion", err)
+	}
+
+	// A save from before the format changed shape still fails on its version
+	var old bytes.Buffer
+	zw := gzip.NewWriter(&old)
+	zw.Write([]byte(`{"version": 0, "dungeons": "not a list"}`))
+	zw.Close()
+	if _, err := readSave(&old); !errors.Is(err, ErrSaveVersion) {
+		t.Errorf("readSave error = %v, expected ErrSaveVersion", err)
+	}
+
+	if _, err := readSave(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Error("readSave accepted a file that isn't a save")
+	}
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"fmt"
+	"github.com/caustin/rrogue/components"
+	level2 "github.com/caustin/rrogue/level"
+)
+
+const (
+	Descend = 1
+	Ascend  = -1
+)
+
+// UseStairs moves the player one level down (Descend) or up (Ascend) when they
+// are standing on the matching stairs. Levels are generated and populated the
+// first time they are reached. Returns true if the player changed level.
+func UseStairs(g *Game, direction int) bool {
+	current := g.Map.CurrentLevel
+
+	for _, result := range g.World.QueryPlayers() {
+		pos := g.World.GetPosition(result)
+		tile := current.Tiles[current.GetIndexFromXY(pos.X, pos.Y)]
+
+		if direction == Descend && tile.TileType != level2.STAIRS_DOWN {
+			g.Systems.UI.AddMessage("There are no stairs down here.\n", "info")
+			return false
+		}
+		if direction == Ascend && tile.TileType != level2.STAIRS_UP {
+			g.Systems.UI.AddMessage("There are no stairs up here.\n", "info")
+			return false
+		}
+
+		dungeon := g.Map.CurrentDungeon
+		depth := g.Map.CurrentDepth + direction
+		next, created := g.Map.LevelAt(depth, g.RNG)
+		if created {
+			g.World.PopulateLevel(next, g.RNG)
+		}
+
+		tile.Blocked = false
+		g.Map.SetCurrentDepth(depth)
+		g.World.SetActiveDepth(depth)
+
+		arrival := next.StairsUp
+		if direction == Ascend {
+			arrival = next.StairsDown
+		}
+		arrival = nearestOpenTile(next, arrival)
+
+		g.World.MoveEntity(result, arrival.X, arrival.Y)
+		next.Tiles[next.GetIndexFromXY(pos.X, pos.Y)].Blocked = true
+		next.PlayerVisible.Compute(next, pos.X, pos.Y, 8)
+
+		verb := "descend"
+		if direction == Ascend {
+			verb = "climb"
+		}
+		g.Systems.UI.AddMessage(fmt.Sprintf("You %s to depth %d.\n", verb, depth), "info")
+		if g.Map.CurrentDungeon != dungeon {
+			g.Systems.UI.AddMessage(fmt.Sprintf("You enter %s.\n", g.Map.Dungeons[g.Map.CurrentDungeon].Name), "info")
+		}
+		return true
+	}
+
+	return false
+}
+
+// nearestOpenTile returns pos if it is free, otherwise the closest walkable tile
+// that nothing is standing on, searching outward from pos.
+func nearestOpenTile(l level2.Level, pos components.Position) components.Position {
+	visited := map[components.Position]bool{pos: true}
+	queue := []components.Position{pos}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		tile := l.Tiles[l.GetIndexFromXY(p.X, p.Y)]
+		if !tile.Blocked {
+			return p
+		}
+
+		for _, d := range []struct{ dx, dy int }{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			n := components.Position{X: p.X + d.dx, Y: p.Y + d.dy}
+			if visited[n] || !l.InBounds(n.X, n.Y) {
+				continue
+			}
+			visited[n] = true
+			if l.Tiles[l.GetIndexFromXY(n.X, n.Y)].TileType != level2.WALL {
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	return pos
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/caustin/rrogue/fonts"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
@@ -20,7 +19,7 @@ var lastText []string = make([]string, 0, 5)
 
 func ProcessUserLog(g *Game, screen *ebiten.Image) {
 	if userLogImg == nil {
-		userLogImg, _, err = ebitenutil.NewImageFromFile("assets/UIPanel.png")
+		userLogImg, err = g.Assets.Image("assets/UIPanel.png")
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -1,212 +1,148 @@
 package level
 
 import (
-	"errors"
+	"container/heap"
 	"github.com/caustin/rrogue/components"
-	"github.com/caustin/rrogue/config"
-	"reflect"
 )
 
-// Node represents a given point on a map
-// g is the total distance of the node from the start
-// h is the estimated distance of the node from the ending
-// f is the total value of the node (g + h)
-type node struct {
-	Parent   *node
-	Position *components.Position
-	g        int
-	h        int
-	f        int
+// pathNode is a tile that the search has reached.
+// g is the cost of the cheapest known path from the start to the tile
+// f is g plus the estimated cost from the tile to the goal
+type pathNode struct {
+	index  int
+	parent int
+	g      int
+	f      int
+	heap   int // Position in the open set, -1 once the node is closed
 }
 
-func (n *node) isEqual(other *node) bool {
-	return n.Position.IsEqual(other.Position)
-}
+// openSet is a min-heap of nodes ordered by f, then by g so that ties go
+// to the node closest to the goal.
+type openSet []*pathNode
 
-func newNode(parent *node, position *components.Position) *node {
-	n := node{}
-	n.Parent = parent
-	n.Position = position
-	n.g = 0
-	n.h = 0
-	n.f = 0
+func (o openSet) Len() int { return len(o) }
 
-	return &n
+func (o openSet) Less(i, j int) bool {
+	if o[i].f == o[j].f {
+		return o[i].g > o[j].g
+	}
+	return o[i].f < o[j].f
 }
 
-func reverseSlice(data interface{}) {
-	value := reflect.ValueOf(data)
-	if value.Kind() != reflect.Slice {
-		panic(errors.New("data must be a slice type"))
-	}
-	valueLen := value.Len()
-	for i := 0; i <= int((valueLen-1)/2); i++ {
-		reverseIndex := valueLen - 1 - i
-		tmp := value.Index(reverseIndex).Interface()
-		value.Index(reverseIndex).Set(value.Index(i))
-		value.Index(i).Set(reflect.ValueOf(tmp))
-	}
+func (o openSet) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+	o[i].heap = i
+	o[j].heap = j
 }
 
-func isInSlice(s []*node, target *node) bool {
-	for _, n := range s {
-		if n.isEqual(target) {
-			return true
-		}
-	}
-	return false
+func (o *openSet) Push(x interface{}) {
+	n := x.(*pathNode)
+	n.heap = len(*o)
+	*o = append(*o, n)
+}
+
+func (o *openSet) Pop() interface{} {
+	old := *o
+	n := old[len(old)-1]
+	old[len(old)-1] = nil
+	n.heap = -1
+	*o = old[:len(old)-1]
+	return n
 }
 
 // AStar implements the AStar Algorithm.
-type AStar struct{}
+// MaxSearch caps how many tiles a search may expand before it gives up and
+// returns no path, bounding the work for distant or unreachable goals. Zero
+// means no limit. Diagonal allows 8-way paths, following the same corner
+// rules as CanStep.
+type AStar struct {
+	MaxSearch int
+	Diagonal  bool
+}
 
 // GetPath takes a level, the starting position and an ending position (the goal) and returns
-// a list of Positions which is the path between the points.
+// a list of Positions which is the path between the points, or nil if there is none.
+// Each step costs the MoveCost of the tile stepped onto, so paths go around walls and
+// locked doors and only go through closed doors when the way around is longer.
 func (as AStar) GetPath(level Level, start *components.Position, end *components.Position) []components.Position {
-	gd := config.NewGameData()
-
-	openList := make([]*node, 0)
-	closedList := make([]*node, 0)
-
-	//Create our starting point
-	startNode := newNode(nil, start)
-	startNode.g = 0
-	startNode.h = 0
-	startNode.f = 0
-
-	//Create this node just for ease of dropping into our isEqual function to see if we are at the end
-	endNodePlaceholder := newNode(nil, end)
-
-	openList = append(openList, startNode)
-
-	for {
-		if len(openList) == 0 {
-			break
-		}
-		//Get the current node
-		currentNode := openList[0]
-		currentIndex := 0
-
-		//Get the node with the smallest f value
-		for index, item := range openList {
-			if item.f < currentNode.f {
-				currentNode = item
-				currentIndex = index
-			}
-		}
-
-		//Move from open to closed list
-		openList = append(openList[:currentIndex], openList[currentIndex+1:]...)
-		closedList = append(closedList, currentNode)
-
-		//Check to see if we reached our end
-		//If so, we are done here
-		if currentNode.isEqual(endNodePlaceholder) {
-			path := make([]components.Position, 0)
-			current := currentNode
-			for {
-				if current == nil {
-					break
-				}
-				path = append(path, *current.Position)
-				current = current.Parent
-			}
-			//Reverse the Path and Return it
-			reverseSlice(path)
-			return path
-		}
-
-		//Ok, if we are here, we are not finished yet
-
-		edges := make([]*node, 0)
-		//Now we get each node in the four cardinal directions
-		//Note:  If you wish to add Diagonal movement, you can do so by getting all 8 positions
-		if currentNode.Position.Y > 0 {
-			tile := level.Tiles[level.GetIndexFromXY(currentNode.Position.X, currentNode.Position.Y-1)]
-			if tile.TileType != WALL {
-				//The location is in the map bounds and is walkable
-				upNodePosition := components.Position{
-					X: currentNode.Position.X,
-					Y: currentNode.Position.Y - 1,
-				}
-				newNode := newNode(currentNode, &upNodePosition)
-				edges = append(edges, newNode)
-
-			}
+	if !level.InBounds(start.X, start.Y) || !level.InBounds(end.X, end.Y) {
+		return nil
+	}
 
+	startIndex := level.GetIndexFromXY(start.X, start.Y)
+	endIndex := level.GetIndexFromXY(end.X, end.Y)
+
+	// Nodes are looked up by tile index, which doubles as the closed set
+	nodes := make([]*pathNode, len(level.Tiles))
+	startNode := &pathNode{index: startIndex, parent: -1, f: as.estimate(start.X, start.Y, end)}
+	nodes[startIndex] = startNode
+	open := &openSet{}
+	heap.Push(open, startNode)
+
+	expanded := 0
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*pathNode)
+		if current.index == endIndex {
+			return as.buildPath(level, nodes, current)
 		}
-		if currentNode.Position.Y < gd.ScreenHeight {
-			tile := level.Tiles[level.GetIndexFromXY(currentNode.Position.X, currentNode.Position.Y+1)]
-			if tile.TileType != WALL {
-				//The location is in the map bounds and is walkable
-				downNodePosition := components.Position{
-					X: currentNode.Position.X,
-					Y: currentNode.Position.Y + 1,
-				}
-				newNode := newNode(currentNode, &downNodePosition)
-				edges = append(edges, newNode)
-
-			}
 
+		expanded++
+		if as.MaxSearch > 0 && expanded > as.MaxSearch {
+			return nil
 		}
-		if currentNode.Position.X > 0 {
-			tile := level.Tiles[level.GetIndexFromXY(currentNode.Position.X-1, currentNode.Position.Y)]
-			if tile.TileType != WALL {
-				//The location is in the map bounds and is walkable
-				leftNodePosition := components.Position{
-					X: currentNode.Position.X - 1,
-					Y: currentNode.Position.Y,
-				}
-				newNode := newNode(currentNode, &leftNodePosition)
-				edges = append(edges, newNode)
 
+		x, y := current.index%level.Width, current.index/level.Width
+		for _, d := range Directions(as.Diagonal) {
+			if !level.CanStep(x, y, d[0], d[1]) {
+				continue
 			}
-
-		}
-		if currentNode.Position.X < gd.ScreenWidth {
-			tile := level.Tiles[level.GetIndexFromXY(currentNode.Position.X+1, currentNode.Position.Y)]
-			if tile.TileType != WALL {
-				//The location is in the map bounds and is walkable
-				rightNodePosition := components.Position{
-					X: currentNode.Position.X + 1,
-					Y: currentNode.Position.Y,
-				}
-				newNode := newNode(currentNode, &rightNodePosition)
-				edges = append(edges, newNode)
-
+			nx, ny := x+d[0], y+d[1]
+			index := level.GetIndexFromXY(nx, ny)
+			tile := level.Tiles[index]
+
+			g := current.g + tile.MoveCost()
+			n := nodes[index]
+			if n == nil {
+				n = &pathNode{index: index, parent: current.index, g: g}
+				n.f = g + as.estimate(nx, ny, end)
+				nodes[index] = n
+				heap.Push(open, n)
+			} else if n.heap >= 0 && g < n.g {
+				// A cheaper way to a tile that is still open
+				n.f -= n.g - g
+				n.g = g
+				n.parent = current.index
+				heap.Fix(open, n.heap)
 			}
-
 		}
+	}
 
-		//Now we iterate through the edges and put them in the open list.
-		for _, edge := range edges {
-			if isInSlice(closedList, edge) {
-				continue
-			}
-
-			edge.g = currentNode.g + 1
-			edge.h = edge.Position.GetManhattanDistance(endNodePlaceholder.Position)
-			edge.f = edge.g + edge.h
-
-			if isInSlice(openList, edge) {
-				//Loop through and check g values
-				isFurther := false
-				for _, n := range openList {
-					if edge.g > n.g {
-						isFurther = true
-						break
-					}
-				}
+	return nil
+}
 
-				if isFurther {
-					continue
-				}
+// estimate is the heuristic: the fewest steps from x,y to end, Manhattan
+// distance for 4-way paths and Chebyshev distance for 8-way ones.
+func (as AStar) estimate(x int, y int, end *components.Position) int {
+	if as.Diagonal {
+		return max(abs(x-end.X), abs(y-end.Y))
+	}
+	return abs(x-end.X) + abs(y-end.Y)
+}
 
-			}
-			openList = append(openList, edge)
+// buildPath follows the parents from the goal back to the start and returns
+// the positions in order from start to goal.
+func (as AStar) buildPath(level Level, nodes []*pathNode, goal *pathNode) []components.Position {
+	path := make([]components.Position, 0)
+	for n := goal; n != nil; {
+		path = append(path, components.Position{X: n.index % level.Width, Y: n.index / level.Width})
+		if n.parent == -1 {
+			break
 		}
-
+		n = nodes[n.parent]
 	}
 
-	return nil
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
 }
@@ -0,0 +1,155 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+// bfsDistance returns the number of steps on the shortest path between two
+// tiles, treating every passable tile as costing one, or -1 if there is none.
+func bfsDistance(level *Level, start components.Position, end components.Position) int {
+	dist := map[int]int{level.GetIndexFromXY(start.X, start.Y): 0}
+	queue := []int{level.GetIndexFromXY(start.X, start.Y)}
+	for len(queue) > 0 {
+		idx := queue[0]
+		queue = queue[1:]
+		if idx == level.GetIndexFromXY(end.X, end.Y) {
+			return dist[idx]
+		}
+		x, y := idx%level.Width, idx/level.Width
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			nx, ny := x+d[0], y+d[1]
+			if !level.InBounds(nx, ny) {
+				continue
+			}
+			n := level.GetIndexFromXY(nx, ny)
+			if _, seen := dist[n]; !seen && level.Tiles[n].IsPassable() {
+				dist[n] = dist[idx] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return -1
+}
+
+// roomCenters returns the centers of the first and last rooms of the level.
+func roomCenters(level *Level) (components.Position, components.Position) {
+	sx, sy := level.Rooms[0].Center()
+	ex, ey := level.Rooms[len(level.Rooms)-1].Center()
+	return components.Position{X: sx, Y: sy}, components.Position{X: ex, Y: ey}
+}
+
+func TestAStarFindsShortestPath(t *testing.T) {
+	for seed := int64(1); seed <= 100; seed++ {
+		level := Level{Width: 80, Height: 50}
+		level.GenerateLevelTiles(RoomsGenerator{}, utils.NewSeededRNG(seed))
+		start, end := roomCenters(&level)
+
+		path := AStar{}.GetPath(level, &start, &end)
+		if len(path) == 0 {
+			t.Fatalf("seed %d: no path between connected rooms", seed)
+		}
+		if !path[0].IsEqual(&start) || !path[len(path)-1].IsEqual(&end) {
+			t.Fatalf("seed %d: path runs from %+v to %+v", seed, path[0], path[len(path)-1])
+		}
+		for i := 1; i < len(path); i++ {
+			if path[i].GetManhattanDistance(&path[i-1]) != 1 {
+				t.Fatalf("seed %d: step %d jumps from %+v to %+v", seed, i, path[i-1], path[i])
+			}
+			if !level.Tiles[level.GetIndexFromXY(path[i].X, path[i].Y)].IsPassable() {
+				t.Fatalf("seed %d: path goes through %+v", seed, path[i])
+			}
+		}
+		if steps := len(path) - 1; steps != bfsDistance(&level, start, end) {
+			t.Errorf("seed %d: path takes %d steps, shortest is %d", seed, steps, bfsDistance(&level, start, end))
+		}
+	}
+}
+
+func TestAStarSameStartAndEnd(t *testing.T) {
+	level := corridorLevel()
+	pos := components.Position{X: 3, Y: 3}
+
+	path := AStar{}.GetPath(level, &pos, &pos)
+	if len(path) != 1 || !path[0].IsEqual(&pos) {
+		t.Errorf("GetPath to itself = %v, expected just the start", path)
+	}
+}
+
+func TestAStarUnreachable(t *testing.T) {
+	level := twoRoomLevel()
+	start, end := roomCenters(&level)
+
+	if path := (AStar{}).GetPath(level, &start, &end); path != nil {
+		t.Errorf("expected no path between unconnected rooms, got %v", path)
+	}
+}
+
+func TestAStarMaxSearch(t *testing.T) {
+	level := corridorLevel()
+	start := components.Position{X: 3, Y: 4}
+	end := components.Position{X: 14, Y: 4}
+
+	if path := (AStar{MaxSearch: 5}).GetPath(level, &start, &end); path != nil {
+		t.Errorf("expected the search to give up, got %v", path)
+	}
+	if path := (AStar{MaxSearch: 500}).GetPath(level, &start, &end); len(path) == 0 {
+		t.Error("expected a path within a generous budget")
+	}
+}
+
+func TestAStarUsesTileCosts(t *testing.T) {
+	// Two ways from the first room to the second: straight through three
+	// closed doors, or four steps longer around a loop below them
+	level := corridorLevel()
+	level.createVerticalTunnel(4, 6, 7)
+	level.createHorizontalTunnel(7, 11, 6)
+	level.createVerticalTunnel(4, 6, 11)
+	for x := 8; x <= 10; x++ {
+		level.Tiles[level.GetIndexFromXY(x, 4)].setDoorState(DoorClosed)
+	}
+	start := components.Position{X: 7, Y: 4}
+	end := components.Position{X: 11, Y: 4}
+
+	path := AStar{}.GetPath(level, &start, &end)
+	if len(path) != 9 {
+		t.Fatalf("expected the 8 step path around the closed doors, got %v", path)
+	}
+
+	for x := 8; x <= 10; x++ {
+		level.Tiles[level.GetIndexFromXY(x, 4)].setDoorState(DoorOpen)
+	}
+	if path := (AStar{}).GetPath(level, &start, &end); len(path) != 5 {
+		t.Errorf("expected the straight path through the open doors, got %v", path)
+	}
+}
+
+// benchmarkLevels are the levels the A* benchmarks search, from the first
+// room to the last.
+var benchmarkLevels = map[string]Generator{
+	"rooms": RoomsGenerator{},
+	"caves": NewCaveGenerator(),
+}
+
+func benchmarkGetPath(b *testing.B, getPath func(Level, *components.Position, *components.Position) []components.Position) {
+	for _, name := range []string{"rooms", "caves"} {
+		b.Run(name, func(b *testing.B) {
+			level := Level{Width: 80, Height: 50}
+			level.GenerateLevelTiles(benchmarkLevels[name], utils.NewSeededRNG(7))
+			start, end := roomCenters(&level)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				getPath(level, &start, &end)
+			}
+		})
+	}
+}
+
+func BenchmarkAStar(b *testing.B) {
+	benchmarkGetPath(b, AStar{}.GetPath)
+}
+
+func BenchmarkLegacyAStar(b *testing.B) {
+	benchmarkGetPath(b, legacyAStar{}.GetPath)
+}
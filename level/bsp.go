@@ -0,0 +1,153 @@
+package level
+
+import "github.com/caustin/rrogue/utils"
+
+// BSPGenerator lays out a level by binary space partitioning: the map is
+// split in two again and again until the pieces are small, a room is placed
+// in every leaf and sibling partitions are joined by a corridor as the
+// recursion unwinds. The result is an evenly filled map of rooms that never
+// overlap.
+type BSPGenerator struct {
+	MinLeafSize int // Smallest width or height a partition may be split into
+	MaxLeafSize int // Partitions larger than this are always split
+	MinRoomSize int // Smallest width or height of a room, walls included
+}
+
+// NewBSPGenerator creates a BSPGenerator with sizes suited to an 80x50 level.
+func NewBSPGenerator() BSPGenerator {
+	return BSPGenerator{MinLeafSize: 8, MaxLeafSize: 20, MinRoomSize: 5}
+}
+
+// bspNode is a partition of the map. Leaves hold a room, inner nodes hold
+// the two halves they were split into.
+type bspNode struct {
+	area        utils.Rect
+	left, right *bspNode
+}
+
+// Generate splits the level and carves the rooms and corridors.
+func (g BSPGenerator) Generate(level *Level, rng utils.RNG) {
+	root := &bspNode{area: utils.NewRect(0, 0, level.Width, level.Height)}
+	g.split(root, rng)
+	g.carve(root, level, rng)
+}
+
+// split recursively divides the node until its pieces are too small to
+// split further. Nodes below MaxLeafSize are only sometimes split, which
+// gives a mix of small and large rooms.
+func (g BSPGenerator) split(node *bspNode, rng utils.RNG) {
+	width := node.area.X2 - node.area.X1
+	height := node.area.Y2 - node.area.Y1
+
+	if width <= g.MaxLeafSize && height <= g.MaxLeafSize && rng.GetDiceRoll(4) == 1 {
+		return
+	}
+
+	// Prefer cutting across the longer side so partitions stay roughly square
+	horizontal := rng.GetDiceRoll(2) == 1
+	if width > height && float64(width)/float64(height) >= 1.25 {
+		horizontal = false
+	} else if height > width && float64(height)/float64(width) >= 1.25 {
+		horizontal = true
+	}
+
+	size := width
+	if horizontal {
+		size = height
+	}
+	if size < g.MinLeafSize*2 {
+		return
+	}
+
+	cut := rng.GetRandomBetween(g.MinLeafSize, size-g.MinLeafSize)
+	a := node.area
+	if horizontal {
+		node.left = &bspNode{area: utils.Rect{X1: a.X1, X2: a.X2, Y1: a.Y1, Y2: a.Y1 + cut}}
+		node.right = &bspNode{area: utils.Rect{X1: a.X1, X2: a.X2, Y1: a.Y1 + cut, Y2: a.Y2}}
+	} else {
+		node.left = &bspNode{area: utils.Rect{X1: a.X1, X2: a.X1 + cut, Y1: a.Y1, Y2: a.Y2}}
+		node.right = &bspNode{area: utils.Rect{X1: a.X1 + cut, X2: a.X2, Y1: a.Y1, Y2: a.Y2}}
+	}
+
+	g.split(node.left, rng)
+	g.split(node.right, rng)
+}
+
+// carve places a room in every leaf below node and joins the two halves of
+// each inner node with a corridor. It returns the rooms it placed.
+func (g BSPGenerator) carve(node *bspNode, level *Level, rng utils.RNG) []utils.Rect {
+	if node.left == nil {
+		room, ok := g.placeRoom(node.area, rng)
+		if !ok {
+			return nil
+		}
+		level.createRoom(room)
+		level.Rooms = append(level.Rooms, room)
+		return []utils.Rect{room}
+	}
+
+	leftRooms := g.carve(node.left, level, rng)
+	rightRooms := g.carve(node.right, level, rng)
+	if len(leftRooms) > 0 && len(rightRooms) > 0 {
+		a, b := closestRooms(leftRooms, rightRooms)
+		level.connectRooms(a, b, rng)
+	}
+	return append(leftRooms, rightRooms...)
+}
+
+// placeRoom picks a random room that fits inside area. The room's walls stay
+// within the area and off its right and bottom edge, so rooms in neighbouring
+// leaves, and the outer wall of the level, never touch.
+func (g BSPGenerator) placeRoom(area utils.Rect, rng utils.RNG) (utils.Rect, bool) {
+	maxW := area.X2 - area.X1 - 1
+	maxH := area.Y2 - area.Y1 - 1
+	if maxW < g.MinRoomSize || maxH < g.MinRoomSize {
+		return utils.Rect{}, false
+	}
+
+	w := rng.GetRandomBetween(g.MinRoomSize, maxW)
+	h := rng.GetRandomBetween(g.MinRoomSize, maxH)
+	x := area.X1 + rng.GetRandomBetween(0, maxW-w)
+	y := area.Y1 + rng.GetRandomBetween(0, maxH-h)
+	return utils.NewRect(x, y, w, h), true
+}
+
+// closestRooms returns the pair of rooms, one from each list, whose centers
+// are nearest to each other.
+func closestRooms(a []utils.Rect, b []utils.Rect) (utils.Rect, utils.Rect) {
+	bestA, bestB := a[0], b[0]
+	best := -1
+	for _, ra := range a {
+		ax, ay := ra.Center()
+		for _, rb := range b {
+			bx, by := rb.Center()
+			d := abs(ax-bx) + abs(ay-by)
+			if best == -1 || d < best {
+				best = d
+				bestA, bestB = ra, rb
+			}
+		}
+	}
+	return bestA, bestB
+}
+
+// connectRooms joins the centers of two rooms with an L-shaped tunnel,
+// choosing at random which way it bends.
+func (level *Level) connectRooms(a utils.Rect, b utils.Rect, rng utils.RNG) {
+	ax, ay := a.Center()
+	bx, by := b.Center()
+	if rng.GetDiceRoll(2) == 2 {
+		level.createHorizontalTunnel(ax, bx, ay)
+		level.createVerticalTunnel(ay, by, bx)
+	} else {
+		level.createVerticalTunnel(ay, by, ax)
+		level.createHorizontalTunnel(ax, bx, by)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,64 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+func TestBSPGenerator(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		level := Level{Width: 80, Height: 50}
+		level.GenerateLevelTiles(NewBSPGenerator(), utils.NewSeededRNG(seed))
+
+		if len(level.Rooms) < 2 {
+			t.Fatalf("seed %d: expected several rooms, got %d", seed, len(level.Rooms))
+		}
+
+		for i, room := range level.Rooms {
+			if room.X1 < 0 || room.Y1 < 0 || room.X2 >= level.Width || room.Y2 >= level.Height {
+				t.Errorf("seed %d: room %d %+v is outside the level", seed, i, room)
+			}
+			for j := i + 1; j < len(level.Rooms); j++ {
+				other := level.Rooms[j]
+				if room.X1 < other.X2 && room.X2 > other.X1 && room.Y1 < other.Y2 && room.Y2 > other.Y1 {
+					t.Errorf("seed %d: rooms %d and %d overlap", seed, i, j)
+				}
+			}
+		}
+
+		if report := level.CheckConnectivity(); !report.Connected() {
+			t.Errorf("seed %d: %d regions are unreachable from the start", seed, len(report.Unreachable))
+		}
+	}
+}
+
+func TestBSPGeneratorIsDeterministic(t *testing.T) {
+	a := Level{Width: 80, Height: 50}
+	a.GenerateLevelTiles(NewBSPGenerator(), utils.NewSeededRNG(42))
+	b := Level{Width: 80, Height: 50}
+	b.GenerateLevelTiles(NewBSPGenerator(), utils.NewSeededRNG(42))
+
+	if len(a.Rooms) != len(b.Rooms) {
+		t.Fatalf("same seed produced %d and %d rooms", len(a.Rooms), len(b.Rooms))
+	}
+	for i := range a.Tiles {
+		if a.Tiles[i].TileType != b.Tiles[i].TileType {
+			t.Fatalf("same seed produced different tiles at index %d", i)
+		}
+	}
+}
+
+func TestGeneratorsProduceDifferentLayouts(t *testing.T) {
+	rooms := Level{Width: 80, Height: 50}
+	rooms.GenerateLevelTiles(RoomsGenerator{}, utils.NewSeededRNG(3))
+	bsp := Level{Width: 80, Height: 50}
+	bsp.GenerateLevelTiles(NewBSPGenerator(), utils.NewSeededRNG(3))
+
+	same := len(rooms.Rooms) == len(bsp.Rooms)
+	for i := 0; same && i < len(rooms.Rooms); i++ {
+		same = rooms.Rooms[i] == bsp.Rooms[i]
+	}
+	if same {
+		t.Error("RoomsGenerator and BSPGenerator produced the same layout")
+	}
+}
@@ -0,0 +1,47 @@
+package level
+
+import "github.com/caustin/rrogue/config"
+
+// Camera is the window of tiles that is drawn to the screen. It follows a
+// target, normally the player, and stops at the edges of the level so that
+// nothing outside the map is shown.
+type Camera struct {
+	X      int // Left most tile column shown
+	Y      int // Top most tile row shown
+	Width  int // Number of tile columns shown
+	Height int // Number of tile rows shown
+}
+
+// NewCamera creates a camera showing width x height tiles.
+func NewCamera(width int, height int) *Camera {
+	return &Camera{Width: width, Height: height}
+}
+
+// Follow centers the camera on the X,Y tile coordinate, clamped so the view
+// stays inside the level. Levels smaller than the view are drawn from the
+// top left corner.
+func (c *Camera) Follow(x int, y int, level Level) {
+	c.X = clamp(x-c.Width/2, 0, level.Width-c.Width)
+	c.Y = clamp(y-c.Height/2, 0, level.Height-c.Height)
+}
+
+// Contains reports whether the X,Y tile coordinate is inside the view.
+func (c *Camera) Contains(x int, y int) bool {
+	return x >= c.X && x < c.X+c.Width && y >= c.Y && y < c.Y+c.Height
+}
+
+// ToScreen converts an X,Y tile coordinate to the pixel position it is drawn at.
+func (c *Camera) ToScreen(x int, y int, gd config.GameData) (float64, float64) {
+	return float64((x - c.X) * gd.TileWidth), float64((y - c.Y) * gd.TileHeight)
+}
+
+// clamp limits v to the range low to high. If high is below low, low wins.
+func clamp(v int, low int, high int) int {
+	if v > high {
+		v = high
+	}
+	if v < low {
+		v = low
+	}
+	return v
+}
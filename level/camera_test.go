@@ -0,0 +1,79 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/config"
+	"testing"
+)
+
+func TestCameraFollow(t *testing.T) {
+	level := Level{Width: 200, Height: 200}
+
+	tests := []struct {
+		name      string
+		x, y      int
+		expectedX int
+		expectedY int
+	}{
+		{
+			name:      "centered on target",
+			x:         100,
+			y:         100,
+			expectedX: 60,
+			expectedY: 75,
+		},
+		{
+			name:      "clamped at top left",
+			x:         3,
+			y:         4,
+			expectedX: 0,
+			expectedY: 0,
+		},
+		{
+			name:      "clamped at bottom right",
+			x:         198,
+			y:         199,
+			expectedX: 120,
+			expectedY: 150,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			camera := NewCamera(80, 50)
+			camera.Follow(tt.x, tt.y, level)
+
+			if camera.X != tt.expectedX || camera.Y != tt.expectedY {
+				t.Errorf("Follow(%d, %d) put camera at (%d, %d), expected (%d, %d)",
+					tt.x, tt.y, camera.X, camera.Y, tt.expectedX, tt.expectedY)
+			}
+
+			if !camera.Contains(tt.x, tt.y) {
+				t.Errorf("Camera does not contain its target (%d, %d)", tt.x, tt.y)
+			}
+		})
+	}
+}
+
+func TestCameraFollowSmallLevel(t *testing.T) {
+	level := Level{Width: 40, Height: 30}
+	camera := NewCamera(80, 50)
+	camera.Follow(35, 25, level)
+
+	if camera.X != 0 || camera.Y != 0 {
+		t.Errorf("Camera on a level smaller than the view = (%d, %d), expected (0, 0)", camera.X, camera.Y)
+	}
+}
+
+func TestCameraToScreen(t *testing.T) {
+	gd := config.NewGameData()
+	camera := &Camera{X: 60, Y: 75, Width: 80, Height: 50}
+
+	x, y := camera.ToScreen(100, 100, gd)
+	if x != float64(40*gd.TileWidth) || y != float64(25*gd.TileHeight) {
+		t.Errorf("ToScreen(100, 100) = (%v, %v), expected (%d, %d)", x, y, 40*gd.TileWidth, 25*gd.TileHeight)
+	}
+
+	if camera.Contains(59, 100) || camera.Contains(140, 100) || camera.Contains(100, 125) {
+		t.Error("Camera contains tiles outside its view")
+	}
+}
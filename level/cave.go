@@ -0,0 +1,218 @@
+package level
+
+import "github.com/caustin/rrogue/utils"
+
+// CaveGenerator lays out natural caverns with a cellular automaton. The map
+// starts as random noise which is smoothed into open caves, disconnected
+// pockets are either filled in or tunnelled to, and a set of spread out
+// clearings is recorded as the level's Rooms so the player, monsters and
+// stairs have somewhere to go.
+type CaveGenerator struct {
+	FillPercent     int // Chance, in percent, that a tile starts as wall
+	SmoothingPasses int // Number of cellular automaton steps
+	MinRegionSize   int // Caves smaller than this are filled in rather than connected
+	SpawnRegions    int // Number of clearings to record as Rooms
+}
+
+// NewCaveGenerator creates a CaveGenerator with settings that give open,
+// winding caves on an 80x50 level.
+func NewCaveGenerator() CaveGenerator {
+	return CaveGenerator{FillPercent: 45, SmoothingPasses: 5, MinRegionSize: 20, SpawnRegions: 12}
+}
+
+// Generate carves the caves and their clearings into the level.
+func (g CaveGenerator) Generate(level *Level, rng utils.RNG) {
+	walls := g.randomFill(level, rng)
+	for i := 0; i < g.SmoothingPasses; i++ {
+		walls = smooth(level, walls)
+	}
+
+	regions := floorRegions(level, walls)
+	kept := make([][]int, 0, len(regions))
+	for _, region := range regions {
+		if len(region) < g.MinRegionSize {
+			for _, idx := range region {
+				walls[idx] = true
+			}
+			continue
+		}
+		kept = append(kept, region)
+	}
+
+	for idx, wall := range walls {
+		if !wall {
+			tile := level.Tiles[idx]
+			tile.Blocked = false
+			tile.TileType = FLOOR
+		}
+	}
+
+	if len(kept) == 0 {
+		// The noise closed up entirely, so open a single cave in the middle
+		level.createRoom(utils.NewRect(level.Width/4, level.Height/4, level.Width/2, level.Height/2))
+	} else {
+		level.connectRegions(kept)
+	}
+
+	g.placeSpawnRegions(level, rng)
+}
+
+// randomFill returns the starting noise. The outer edge is always wall.
+func (g CaveGenerator) randomFill(level *Level, rng utils.RNG) []bool {
+	walls := make([]bool, level.Width*level.Height)
+	for y := 0; y < level.Height; y++ {
+		for x := 0; x < level.Width; x++ {
+			edge := x == 0 || y == 0 || x == level.Width-1 || y == level.Height-1
+			walls[level.GetIndexFromXY(x, y)] = edge || rng.GetRandomInt(100) < g.FillPercent
+		}
+	}
+	return walls
+}
+
+// smooth runs one step of the automaton: a tile becomes wall when most of its
+// eight neighbours are wall and floor when most are floor.
+func smooth(level *Level, walls []bool) []bool {
+	next := make([]bool, len(walls))
+	for y := 0; y < level.Height; y++ {
+		for x := 0; x < level.Width; x++ {
+			idx := level.GetIndexFromXY(x, y)
+			if x == 0 || y == 0 || x == level.Width-1 || y == level.Height-1 {
+				next[idx] = true
+				continue
+			}
+
+			count := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && walls[level.GetIndexFromXY(x+dx, y+dy)] {
+						count++
+					}
+				}
+			}
+
+			switch {
+			case count > 4:
+				next[idx] = true
+			case count < 4:
+				next[idx] = false
+			default:
+				next[idx] = walls[idx]
+			}
+		}
+	}
+	return next
+}
+
+// floorRegions groups the floor tiles into regions that are connected by
+// orthogonal steps, largest first. Each region is a list of tile indexes.
+func floorRegions(level *Level, walls []bool) [][]int {
+	seen := make([]bool, len(walls))
+	regions := make([][]int, 0)
+
+	for start := range walls {
+		if walls[start] || seen[start] {
+			continue
+		}
+
+		region := []int{start}
+		seen[start] = true
+		for i := 0; i < len(region); i++ {
+			x, y := region[i]%level.Width, region[i]/level.Width
+			for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+				nx, ny := x+d[0], y+d[1]
+				if !level.InBounds(nx, ny) {
+					continue
+				}
+				n := level.GetIndexFromXY(nx, ny)
+				if !walls[n] && !seen[n] {
+					seen[n] = true
+					region = append(region, n)
+				}
+			}
+		}
+
+		// Insert in order of size, keeping discovery order for ties
+		pos := len(regions)
+		for pos > 0 && len(regions[pos-1]) < len(region) {
+			pos--
+		}
+		regions = append(regions, nil)
+		copy(regions[pos+1:], regions[pos:])
+		regions[pos] = region
+	}
+
+	return regions
+}
+
+// connectRegions joins every region to the largest by tunnelling from each
+// one to the closest tile that is already connected.
+func (level *Level) connectRegions(regions [][]int) {
+	connected := append([]int{}, regions[0]...)
+
+	for _, region := range regions[1:] {
+		from, to := region[0], connected[0]
+		best := -1
+		for _, a := range region {
+			ax, ay := a%level.Width, a/level.Width
+			for _, b := range connected {
+				bx, by := b%level.Width, b/level.Width
+				if d := abs(ax-bx) + abs(ay-by); best == -1 || d < best {
+					best = d
+					from, to = a, b
+				}
+			}
+		}
+
+		fx, fy := from%level.Width, from/level.Width
+		tx, ty := to%level.Width, to/level.Width
+		level.createHorizontalTunnel(fx, tx, fy)
+		level.createVerticalTunnel(fy, ty, tx)
+		connected = append(connected, region...)
+	}
+}
+
+// placeSpawnRegions records spread out 3x3 clearings as the level's Rooms.
+// Each one is centred on a floor tile chosen to be as far as possible from
+// the clearings before it, and is carved out so it is all floor.
+func (g CaveGenerator) placeSpawnRegions(level *Level, rng utils.RNG) {
+	candidates := make([]int, 0)
+	for y := 2; y < level.Height-2; y++ {
+		for x := 2; x < level.Width-2; x++ {
+			if level.Tiles[level.GetIndexFromXY(x, y)].TileType != WALL {
+				candidates = append(candidates, level.GetIndexFromXY(x, y))
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	const samples = 20
+	const minSpacing = 5
+
+	for len(level.Rooms) < g.SpawnRegions {
+		best, bestDistance := -1, -1
+		for i := 0; i < samples; i++ {
+			c := candidates[rng.GetRandomInt(len(candidates))]
+			cx, cy := c%level.Width, c/level.Width
+			distance := level.Width + level.Height
+			for _, room := range level.Rooms {
+				rx, ry := room.Center()
+				distance = min(distance, abs(cx-rx)+abs(cy-ry))
+			}
+			if distance > bestDistance {
+				best, bestDistance = c, distance
+			}
+		}
+
+		if bestDistance < minSpacing {
+			// The caves are too cramped for another clearing
+			break
+		}
+
+		x, y := best%level.Width, best/level.Width
+		room := utils.NewRect(x-2, y-2, 4, 4)
+		level.createRoom(room)
+		level.Rooms = append(level.Rooms, room)
+	}
+}
@@ -0,0 +1,71 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+func TestCaveGenerator(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		level := Level{Width: 80, Height: 50}
+		level.GenerateLevelTiles(NewCaveGenerator(), utils.NewSeededRNG(seed))
+
+		if len(level.Rooms) < 2 {
+			t.Fatalf("seed %d: expected several spawn regions, got %d", seed, len(level.Rooms))
+		}
+
+		for i, room := range level.Rooms {
+			for y := room.Y1 + 1; y < room.Y2; y++ {
+				for x := room.X1 + 1; x < room.X2; x++ {
+					if level.Tiles[level.GetIndexFromXY(x, y)].TileType == WALL {
+						t.Errorf("seed %d: spawn region %d has a wall at %d,%d", seed, i, x, y)
+					}
+				}
+			}
+		}
+
+		for x := 0; x < level.Width; x++ {
+			for _, y := range []int{0, level.Height - 1} {
+				if level.Tiles[level.GetIndexFromXY(x, y)].TileType != WALL {
+					t.Fatalf("seed %d: outer wall is open at %d,%d", seed, x, y)
+				}
+			}
+		}
+
+		if report := level.CheckConnectivity(); !report.Connected() {
+			t.Errorf("seed %d: %d regions are unreachable from the start", seed, len(report.Unreachable))
+		}
+	}
+}
+
+func TestCaveGeneratorPlacesStairs(t *testing.T) {
+	level := Level{Width: 80, Height: 50, Depth: 2}
+	level.GenerateLevelTiles(NewCaveGenerator(), utils.NewSeededRNG(9))
+	level.placeStairs(utils.NewSeededRNG(9))
+
+	if level.Tiles[level.GetIndexFromXY(level.StairsDown.X, level.StairsDown.Y)].TileType != STAIRS_DOWN {
+		t.Error("expected down stairs in the last spawn region")
+	}
+	if level.Tiles[level.GetIndexFromXY(level.StairsUp.X, level.StairsUp.Y)].TileType != STAIRS_UP {
+		t.Error("expected up stairs in the first spawn region")
+	}
+}
+
+func TestSmoothFillsIsolatedWalls(t *testing.T) {
+	level := Level{Width: 5, Height: 5}
+	walls := make([]bool, 25)
+	for i := range walls {
+		x, y := i%5, i/5
+		walls[i] = x == 0 || y == 0 || x == 4 || y == 4
+	}
+	walls[level.GetIndexFromXY(2, 2)] = true
+
+	walls = smooth(&level, walls)
+
+	if walls[level.GetIndexFromXY(2, 2)] {
+		t.Error("a lone wall surrounded by floor should become floor")
+	}
+	if !walls[level.GetIndexFromXY(0, 2)] {
+		t.Error("the outer edge should stay wall")
+	}
+}
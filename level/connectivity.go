@@ -0,0 +1,90 @@
+package level
+
+import "github.com/caustin/rrogue/components"
+
+// ConnectivityReport describes which walkable tiles of a level can be reached
+// from where the player starts.
+type ConnectivityReport struct {
+	Start       components.Position     // Center of the first room, where the player starts
+	Reachable   int                     // Number of walkable tiles reachable from Start
+	Unreachable [][]components.Position // Walkable regions that cannot be reached from Start
+}
+
+// Connected reports whether the start is walkable and every walkable tile can
+// be reached from it.
+func (r ConnectivityReport) Connected() bool {
+	return r.Reachable > 0 && len(r.Unreachable) == 0
+}
+
+// CheckConnectivity flood-fills the level from the center of its first room
+// and reports every walkable region that the fill did not reach. A level with
+// no rooms has nowhere to start and is never connected.
+func (level *Level) CheckConnectivity() ConnectivityReport {
+	report := ConnectivityReport{}
+	if len(level.Rooms) == 0 {
+		return report
+	}
+
+	x, y := level.Rooms[0].Center()
+	report.Start = components.Position{X: x, Y: y}
+	start := level.GetIndexFromXY(x, y)
+
+	for _, region := range floorRegions(level, level.walls()) {
+		if containsIndex(region, start) {
+			report.Reachable = len(region)
+			continue
+		}
+
+		positions := make([]components.Position, len(region))
+		for i, idx := range region {
+			positions[i] = components.Position{X: idx % level.Width, Y: idx / level.Width}
+		}
+		report.Unreachable = append(report.Unreachable, positions)
+	}
+
+	return report
+}
+
+// RepairConnectivity tunnels from every region that cannot be reached from
+// the start room to the closest reachable tile, so afterwards the level is
+// fully connected. Levels without rooms are left alone.
+func (level *Level) RepairConnectivity() {
+	if len(level.Rooms) == 0 {
+		return
+	}
+
+	x, y := level.Rooms[0].Center()
+	start := level.GetIndexFromXY(x, y)
+	if tile := level.Tiles[start]; tile.TileType == WALL {
+		// The start itself must be walkable
+		tile.Blocked = false
+		tile.TileType = FLOOR
+	}
+
+	regions := floorRegions(level, level.walls())
+	for i, region := range regions {
+		if containsIndex(region, start) {
+			regions[0], regions[i] = regions[i], regions[0]
+			break
+		}
+	}
+	level.connectRegions(regions)
+}
+
+// walls returns, for every tile index, whether the tile is a wall.
+func (level *Level) walls() []bool {
+	walls := make([]bool, len(level.Tiles))
+	for i, tile := range level.Tiles {
+		walls[i] = tile.TileType == WALL
+	}
+	return walls
+}
+
+func containsIndex(indexes []int, idx int) bool {
+	for _, i := range indexes {
+		if i == idx {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+// twoRoomLevel returns a wall level with two rooms and no tunnel between them.
+func twoRoomLevel() Level {
+	level := Level{Width: 30, Height: 20}
+	level.Tiles = level.createTiles()
+	level.Rooms = []utils.Rect{utils.NewRect(1, 1, 6, 6), utils.NewRect(20, 10, 6, 6)}
+	for _, room := range level.Rooms {
+		level.createRoom(room)
+	}
+	return level
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	level := twoRoomLevel()
+
+	report := level.CheckConnectivity()
+	if report.Connected() {
+		t.Fatal("expected two unconnected rooms to be reported as disconnected")
+	}
+	if report.Start.X != 4 || report.Start.Y != 4 {
+		t.Errorf("Start = %+v, expected the center of the first room (4,4)", report.Start)
+	}
+	if report.Reachable != 25 {
+		t.Errorf("Reachable = %d, expected the 25 tiles of the first room", report.Reachable)
+	}
+	if len(report.Unreachable) != 1 || len(report.Unreachable[0]) != 25 {
+		t.Errorf("expected one unreachable region of 25 tiles, got %v", report.Unreachable)
+	}
+
+	level.createHorizontalTunnel(4, 23, 4)
+	level.createVerticalTunnel(4, 13, 23)
+	if report := level.CheckConnectivity(); !report.Connected() {
+		t.Errorf("expected the rooms to be connected after tunnelling, %d regions unreachable", len(report.Unreachable))
+	}
+}
+
+func TestCheckConnectivityWithoutRooms(t *testing.T) {
+	level := Level{Width: 10, Height: 10}
+	level.Tiles = level.createTiles()
+
+	if level.CheckConnectivity().Connected() {
+		t.Error("a level without rooms has no start and should not be connected")
+	}
+}
+
+func TestRepairConnectivity(t *testing.T) {
+	level := twoRoomLevel()
+	level.RepairConnectivity()
+
+	if report := level.CheckConnectivity(); !report.Connected() {
+		t.Errorf("expected the level to be connected after repair, %d regions unreachable", len(report.Unreachable))
+	}
+}
+
+// brokenGenerator only connects its rooms once it has been asked for a
+// layout more than failures times.
+type brokenGenerator struct {
+	failures int
+	calls    int
+}
+
+func (g *brokenGenerator) Generate(level *Level, rng utils.RNG) {
+	g.calls++
+	*level = twoRoomLevel()
+	if g.calls > g.failures {
+		level.RepairConnectivity()
+	}
+}
+
+func TestGenerateLevelTilesRetries(t *testing.T) {
+	gen := &brokenGenerator{failures: 2}
+	level := Level{Width: 30, Height: 20}
+	level.GenerateLevelTiles(gen, utils.NewSeededRNG(1))
+
+	if gen.calls != 3 {
+		t.Errorf("generator called %d times, expected 3", gen.calls)
+	}
+	if !level.CheckConnectivity().Connected() {
+		t.Error("expected a connected level")
+	}
+}
+
+func TestGenerateLevelTilesRepairs(t *testing.T) {
+	gen := &brokenGenerator{failures: maxGenerationAttempts}
+	level := Level{Width: 30, Height: 20}
+	level.GenerateLevelTiles(gen, utils.NewSeededRNG(1))
+
+	if gen.calls != maxGenerationAttempts {
+		t.Errorf("generator called %d times, expected %d", gen.calls, maxGenerationAttempts)
+	}
+	if !level.CheckConnectivity().Connected() {
+		t.Error("expected the level to be repaired")
+	}
+}
+
+// TestGeneratedLevelsAreConnected generates thousands of seeded levels with
+// every generator and checks that the whole level, stairs included, can be
+// reached from the start without needing a repair.
+func TestGeneratedLevelsAreConnected(t *testing.T) {
+	seeds := int64(2000)
+	if testing.Short() {
+		seeds = 100
+	}
+
+	generators := map[string]Generator{
+		"rooms": RoomsGenerator{},
+		"bsp":   NewBSPGenerator(),
+		"cave":  NewCaveGenerator(),
+	}
+
+	for name, gen := range generators {
+		t.Run(name, func(t *testing.T) {
+			for seed := int64(1); seed <= seeds; seed++ {
+				rng := utils.NewSeededRNG(seed)
+				level := Level{Width: 80, Height: 50, Depth: 2}
+				level.Tiles = level.createTiles()
+				gen.Generate(&level, rng)
+
+				report := level.CheckConnectivity()
+				if !report.Connected() {
+					t.Fatalf("seed %d: %d regions unreachable from %+v", seed, len(report.Unreachable), report.Start)
+				}
+
+				level.placeStairs(rng)
+				if !level.CheckConnectivity().Connected() {
+					t.Fatalf("seed %d: stairs are not reachable", seed)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package level
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/caustin/rrogue/components"
+)
+
+// FleeCoefficient scales an approach map into a flee map. Being negative
+// turns the goals into peaks, and being larger than one makes the far
+// reaches of the level more attractive than simply the nearest corner.
+const FleeCoefficient = -1.2
+
+// Unreachable is the value of tiles from which no goal can be reached.
+var Unreachable = math.Inf(1)
+
+// DijkstraMap is a distance field over a level, sometimes called a flow
+// field. Every tile holds the cost of the cheapest path from it to the
+// nearest goal, so anything following the map only needs to step to its
+// lowest neighbour, rolling downhill, to head for a goal. A single map is
+// shared by every monster chasing the same goals.
+type DijkstraMap struct {
+	Width    int
+	Height   int
+	Values   []float64 // Indexed like Level.Tiles
+	Diagonal bool      // Whether the map was built for 8-way movement
+}
+
+// WeightedMap pairs a map with how strongly it pulls when maps are combined.
+// Negative weights push away from the map's goals.
+type WeightedMap struct {
+	Map    DijkstraMap
+	Weight float64
+}
+
+// dijkstraEntry is a tile waiting in the queue with the value it was queued at.
+type dijkstraEntry struct {
+	index int
+	value float64
+}
+
+// dijkstraQueue is a min-heap of tiles ordered by value.
+type dijkstraQueue []dijkstraEntry
+
+func (q dijkstraQueue) Len() int            { return len(q) }
+func (q dijkstraQueue) Less(i, j int) bool  { return q[i].value < q[j].value }
+func (q dijkstraQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *dijkstraQueue) Push(x interface{}) { *q = append(*q, x.(dijkstraEntry)) }
+
+func (q *dijkstraQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// NewDijkstraMap builds a map of the level towards one or many goals. Steps
+// cost the MoveCost of the tile stepped onto and follow the same rules as
+// CanStep, so the map agrees with AStar.
+func NewDijkstraMap(level Level, goals []components.Position, diagonal bool) DijkstraMap {
+	d := newDijkstraMap(level, diagonal)
+	for _, goal := range goals {
+		if level.InBounds(goal.X, goal.Y) {
+			d.Values[level.GetIndexFromXY(goal.X, goal.Y)] = 0
+		}
+	}
+	d.relax(level)
+	return d
+}
+
+func newDijkstraMap(level Level, diagonal bool) DijkstraMap {
+	d := DijkstraMap{Width: level.Width, Height: level.Height, Diagonal: diagonal}
+	d.Values = make([]float64, len(level.Tiles))
+	for i := range d.Values {
+		d.Values[i] = Unreachable
+	}
+	return d
+}
+
+// relax spreads the values outward until every tile holds the cheapest cost
+// of reaching one of the tiles that already had a value. Starting values
+// may be negative, which is what makes flee maps work.
+func (d *DijkstraMap) relax(level Level) {
+	queue := &dijkstraQueue{}
+	for i, v := range d.Values {
+		if v != Unreachable {
+			heap.Push(queue, dijkstraEntry{index: i, value: v})
+		}
+	}
+
+	for queue.Len() > 0 {
+		e := heap.Pop(queue).(dijkstraEntry)
+		if e.value > d.Values[e.index] {
+			// Already reached more cheaply since this entry was queued
+			continue
+		}
+
+		x, y := e.index%d.Width, e.index/d.Width
+		cost := e.value + float64(level.Tiles[e.index].MoveCost())
+		for _, dir := range Directions(d.Diagonal) {
+			// Values flow to the tiles that could step onto this one
+			nx, ny := x+dir[0], y+dir[1]
+			if !level.InBounds(nx, ny) || !level.Tiles[level.GetIndexFromXY(nx, ny)].IsPassable() {
+				continue
+			}
+			if !level.CanStep(nx, ny, -dir[0], -dir[1]) {
+				continue
+			}
+			n := level.GetIndexFromXY(nx, ny)
+			if cost < d.Values[n] {
+				d.Values[n] = cost
+				heap.Push(queue, dijkstraEntry{index: n, value: cost})
+			}
+		}
+	}
+}
+
+// Value returns the map's value at x,y.
+func (d DijkstraMap) Value(x int, y int) float64 {
+	return d.Values[y*d.Width+x]
+}
+
+// Flee returns the map for getting away from this map's goals. Following it
+// leads away from the goals towards wherever is furthest from them, rather
+// than into the nearest dead end.
+func (d DijkstraMap) Flee(level Level) DijkstraMap {
+	f := newDijkstraMap(level, d.Diagonal)
+	for i, v := range d.Values {
+		if v != Unreachable {
+			f.Values[i] = v * FleeCoefficient
+		}
+	}
+	f.relax(level)
+	return f
+}
+
+// Combine adds the weighted maps together into one, so for example a monster
+// can be drawn to the player and to items at the same time. Tiles that any of
+// the maps cannot reach stay unreachable. All the maps must be of the same
+// level.
+func Combine(maps ...WeightedMap) DijkstraMap {
+	if len(maps) == 0 {
+		return DijkstraMap{}
+	}
+
+	first := maps[0].Map
+	c := DijkstraMap{Width: first.Width, Height: first.Height, Diagonal: first.Diagonal}
+	c.Values = make([]float64, len(first.Values))
+	for _, wm := range maps {
+		for i, v := range wm.Map.Values {
+			if v == Unreachable || c.Values[i] == Unreachable {
+				c.Values[i] = Unreachable
+				continue
+			}
+			c.Values[i] += v * wm.Weight
+		}
+	}
+	return c
+}
+
+// Downhill returns the neighbour of x,y with the lowest value, as long as it
+// is lower than x,y itself and nothing is standing on it. Closed doors count
+// as free, since they can be opened. ok is false when there is nowhere
+// better to go.
+func (d DijkstraMap) Downhill(level Level, x int, y int) (next components.Position, ok bool) {
+	best := d.Value(x, y)
+	for _, dir := range Directions(d.Diagonal) {
+		nx, ny := x+dir[0], y+dir[1]
+		if !level.CanStep(x, y, dir[0], dir[1]) {
+			continue
+		}
+
+		tile := level.Tiles[level.GetIndexFromXY(nx, ny)]
+		occupied := tile.Blocked && !(tile.TileType == DOOR && tile.DoorState != DoorOpen)
+		if occupied {
+			continue
+		}
+
+		if v := d.Value(nx, ny); v < best {
+			best = v
+			next = components.Position{X: nx, Y: ny}
+			ok = true
+		}
+	}
+	return next, ok
+}
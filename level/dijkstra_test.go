@@ -0,0 +1,166 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+func TestDijkstraMapMatchesShortestPaths(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		level := Level{Width: 80, Height: 50}
+		level.GenerateLevelTiles(RoomsGenerator{}, utils.NewSeededRNG(seed))
+		goal, _ := roomCenters(&level)
+		d := NewDijkstraMap(level, []components.Position{goal}, false)
+
+		for _, room := range level.Rooms {
+			x, y := room.Center()
+			expected := bfsDistance(&level, components.Position{X: x, Y: y}, goal)
+			if v := d.Value(x, y); v != float64(expected) {
+				t.Errorf("seed %d: value at %d,%d is %v, shortest path is %d", seed, x, y, v, expected)
+			}
+		}
+	}
+}
+
+func TestDijkstraMapMultipleGoals(t *testing.T) {
+	level := openLevel()
+	a := components.Position{X: 1, Y: 1}
+	b := components.Position{X: 10, Y: 10}
+
+	both := NewDijkstraMap(level, []components.Position{a, b}, false)
+	toA := NewDijkstraMap(level, []components.Position{a}, false)
+	toB := NewDijkstraMap(level, []components.Position{b}, false)
+
+	for i, v := range both.Values {
+		expected := toA.Values[i]
+		if toB.Values[i] < expected {
+			expected = toB.Values[i]
+		}
+		if v != expected {
+			t.Fatalf("value at tile %d is %v, expected the nearer goal's %v", i, v, expected)
+		}
+	}
+	if both.Value(1, 1) != 0 || both.Value(10, 10) != 0 {
+		t.Error("goals should have a value of 0")
+	}
+}
+
+func TestDijkstraMapUnreachable(t *testing.T) {
+	level := corridorLevel()
+	level.Tiles[level.GetIndexFromXY(9, 4)].TileType = WALL
+	d := NewDijkstraMap(level, []components.Position{{X: 3, Y: 3}}, false)
+
+	if v := d.Value(14, 3); v != Unreachable {
+		t.Errorf("tile behind the blocked corridor has value %v, expected Unreachable", v)
+	}
+	if v := d.Value(0, 0); v != Unreachable {
+		t.Errorf("wall has value %v, expected Unreachable", v)
+	}
+}
+
+func TestDijkstraMapUsesTileCosts(t *testing.T) {
+	level := corridorLevel()
+	level.Tiles[level.GetIndexFromXY(9, 4)].setDoorState(DoorClosed)
+	d := NewDijkstraMap(level, []components.Position{{X: 3, Y: 4}}, false)
+
+	// Eleven steps, one of them onto the closed door
+	expected := 10 + level.Tiles[level.GetIndexFromXY(9, 4)].MoveCost()
+	if v := d.Value(14, 4); v != float64(expected) {
+		t.Errorf("value beyond the closed door is %v, expected %d", v, expected)
+	}
+}
+
+func TestDijkstraMapDiagonal(t *testing.T) {
+	level := openLevel()
+	goal := []components.Position{{X: 2, Y: 2}}
+
+	if v := NewDijkstraMap(level, goal, false).Value(4, 4); v != 4 {
+		t.Errorf("4-way value at 4,4 is %v, expected 4", v)
+	}
+	if v := NewDijkstraMap(level, goal, true).Value(4, 4); v != 2 {
+		t.Errorf("8-way value at 4,4 is %v, expected 2", v)
+	}
+}
+
+func TestDownhill(t *testing.T) {
+	level := openLevel()
+	d := NewDijkstraMap(level, []components.Position{{X: 2, Y: 2}}, false)
+
+	next, ok := d.Downhill(level, 2, 5)
+	if !ok || next != (components.Position{X: 2, Y: 4}) {
+		t.Fatalf("Downhill(2, 5) = %+v, %v, expected 2,4", next, ok)
+	}
+
+	// Something standing in the way is stepped around
+	level.Tiles[level.GetIndexFromXY(2, 4)].Blocked = true
+	if next, ok = d.Downhill(level, 2, 5); ok {
+		t.Errorf("Downhill(2, 5) = %+v with the only lower tile occupied, expected no step", next)
+	}
+	if next, ok = d.Downhill(level, 3, 4); !ok || next != (components.Position{X: 3, Y: 3}) {
+		t.Errorf("Downhill(3, 4) = %+v, %v, expected 3,3", next, ok)
+	}
+
+	if _, ok = d.Downhill(level, 2, 2); ok {
+		t.Error("Downhill at the goal should not step")
+	}
+}
+
+func TestFleeLeadsAway(t *testing.T) {
+	level := corridorLevel()
+	goal := components.Position{X: 3, Y: 4}
+	toGoal := NewDijkstraMap(level, []components.Position{goal}, false)
+	flee := toGoal.Flee(level)
+
+	// Follow the flee map from beside the goal until it settles
+	pos := components.Position{X: 4, Y: 4}
+	for i := 0; i < 50; i++ {
+		next, ok := flee.Downhill(level, pos.X, pos.Y)
+		if !ok {
+			break
+		}
+		if toGoal.Value(next.X, next.Y) < toGoal.Value(pos.X, pos.Y)-1 {
+			t.Fatalf("fleeing from %+v to %+v moved towards the goal", pos, next)
+		}
+		pos = next
+	}
+
+	if pos.X < 12 {
+		t.Errorf("fleeing stopped at %+v, expected to reach the far room", pos)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	level := corridorLevel()
+	left := NewDijkstraMap(level, []components.Position{{X: 2, Y: 4}}, false)
+	right := NewDijkstraMap(level, []components.Position{{X: 15, Y: 4}}, false)
+
+	// A strong pull right beats a weak pull left
+	c := Combine(WeightedMap{Map: left, Weight: 0.5}, WeightedMap{Map: right, Weight: 1})
+	next, ok := c.Downhill(level, 9, 4)
+	if !ok || next != (components.Position{X: 10, Y: 4}) {
+		t.Errorf("Downhill(9, 4) = %+v, %v, expected 10,4", next, ok)
+	}
+
+	expected := left.Value(9, 4)*0.5 + right.Value(9, 4)
+	if v := c.Value(9, 4); v != expected {
+		t.Errorf("combined value is %v, expected %v", v, expected)
+	}
+	if v := c.Value(0, 0); v != Unreachable {
+		t.Errorf("wall has combined value %v, expected Unreachable", v)
+	}
+}
+
+func BenchmarkDijkstraMap(b *testing.B) {
+	for _, name := range []string{"rooms", "caves"} {
+		b.Run(name, func(b *testing.B) {
+			level := Level{Width: 80, Height: 50}
+			level.GenerateLevelTiles(benchmarkLevels[name], utils.NewSeededRNG(7))
+			goal, _ := roomCenters(&level)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				NewDijkstraMap(level, []components.Position{goal}, false)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/utils"
+)
+
+// maxLockedDoors is the most locked doors, and so keys, a level can have.
+const maxLockedDoors = 2
+
+// closedDoorCost is the extra cost for a path to go through a closed door,
+// which takes a turn to open.
+const closedDoorCost = 2
+
+// IsPassable reports whether something could walk onto the tile, ignoring
+// whatever may be standing on it. Walls and locked doors are impassable;
+// closed doors are passable once opened.
+func (t *MapTile) IsPassable() bool {
+	if t.TileType == WALL {
+		return false
+	}
+	return t.TileType != DOOR || t.DoorState != DoorLocked
+}
+
+// MoveCost is the cost of stepping onto the tile when planning a path.
+func (t *MapTile) MoveCost() int {
+	if t.TileType == DOOR && t.DoorState == DoorClosed {
+		return 1 + closedDoorCost
+	}
+	return 1
+}
+
+// OpenDoor opens the closed door at x,y. Locked doors must be unlocked first.
+// Returns true if the door was opened.
+func (level *Level) OpenDoor(x int, y int) bool {
+	tile := level.Tiles[level.GetIndexFromXY(x, y)]
+	if tile.TileType != DOOR || tile.DoorState != DoorClosed {
+		return false
+	}
+	tile.setDoorState(DoorOpen)
+	return true
+}
+
+// CloseDoor closes the open door at x,y. A door with something standing in
+// it can't be closed. Returns true if the door was closed.
+func (level *Level) CloseDoor(x int, y int) bool {
+	tile := level.Tiles[level.GetIndexFromXY(x, y)]
+	if tile.TileType != DOOR || tile.DoorState != DoorOpen || tile.Blocked {
+		return false
+	}
+	tile.setDoorState(DoorClosed)
+	return true
+}
+
+// UnlockDoor unlocks the locked door at x,y, leaving it closed. Returns true
+// if the door was unlocked.
+func (level *Level) UnlockDoor(x int, y int) bool {
+	tile := level.Tiles[level.GetIndexFromXY(x, y)]
+	if tile.TileType != DOOR || tile.DoorState != DoorLocked {
+		return false
+	}
+	tile.setDoorState(DoorClosed)
+	return true
+}
+
+func (t *MapTile) setDoorState(state DoorState) {
+	t.TileType = DOOR
+	t.DoorState = state
+	t.Blocked = state != DoorOpen
+}
+
+// placeDoors puts a door in every doorway where a tunnel meets a room. About
+// a third are left open, the rest are closed and a few of those are locked.
+// A key for each locked door, including any drawn in a vault, is placed where
+// the player can reach it without going through a locked door.
+func (level *Level) placeDoors(rng utils.RNG) {
+	lockedDoorways := 0
+	for _, room := range level.Rooms {
+		for _, pos := range roomPerimeter(room) {
+			if !level.isDoorway(pos.X, pos.Y) {
+				continue
+			}
+
+			state := DoorClosed
+			if rng.GetDiceRoll(3) == 1 {
+				state = DoorOpen
+			} else if lockedDoorways < maxLockedDoors && rng.GetDiceRoll(6) == 1 {
+				state = DoorLocked
+				lockedDoorways++
+			}
+			level.Tiles[level.GetIndexFromXY(pos.X, pos.Y)].setDoorState(state)
+		}
+	}
+
+	locked := 0
+	for _, tile := range level.Tiles {
+		if tile.TileType == DOOR && tile.DoorState == DoorLocked {
+			locked++
+		}
+	}
+	level.placeKeys(locked, rng)
+}
+
+// roomPerimeter returns the positions of a room's walls, in a fixed order.
+func roomPerimeter(room utils.Rect) []components.Position {
+	perimeter := make([]components.Position, 0)
+	for x := room.X1; x <= room.X2; x++ {
+		perimeter = append(perimeter, components.Position{X: x, Y: room.Y1}, components.Position{X: x, Y: room.Y2})
+	}
+	for y := room.Y1 + 1; y < room.Y2; y++ {
+		perimeter = append(perimeter, components.Position{X: room.X1, Y: y}, components.Position{X: room.X2, Y: y})
+	}
+	return perimeter
+}
+
+// isDoorway reports whether x,y is a floor tile that is a one tile wide gap
+// in a wall: walls on two opposite sides, open ground on the other two, and
+// no door already next to it.
+func (level *Level) isDoorway(x int, y int) bool {
+	if x <= 0 || y <= 0 || x >= level.Width-1 || y >= level.Height-1 {
+		return false
+	}
+	if level.Tiles[level.GetIndexFromXY(x, y)].TileType != FLOOR {
+		return false
+	}
+
+	tileType := func(dx, dy int) TileType {
+		return level.Tiles[level.GetIndexFromXY(x+dx, y+dy)].TileType
+	}
+	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		if tileType(d[0], d[1]) == DOOR {
+			return false
+		}
+	}
+
+	open := func(dx, dy int) bool {
+		return tileType(dx, dy) != WALL
+	}
+	horizontal := !open(-1, 0) && !open(1, 0) && open(0, -1) && open(0, 1)
+	vertical := !open(0, -1) && !open(0, 1) && open(-1, 0) && open(1, 0)
+	return horizontal || vertical
+}
+
+// placeKeys puts count keys on floor tiles that can be reached from the start
+// room without passing a locked door, so every locked door can be opened.
+// Room centers and spawn points, where the player and monsters start, are
+// avoided.
+func (level *Level) placeKeys(count int, rng utils.RNG) {
+	level.Keys = make([]components.Position, 0, count)
+	if count == 0 || len(level.Rooms) == 0 {
+		return
+	}
+
+	centers := make(map[int]bool)
+	for _, room := range level.Rooms {
+		x, y := room.Center()
+		centers[level.GetIndexFromXY(x, y)] = true
+	}
+	for _, spawn := range level.SpawnPoints {
+		centers[level.GetIndexFromXY(spawn.Position.X, spawn.Position.Y)] = true
+	}
+
+	candidates := make([]int, 0)
+	for _, idx := range level.reachableWithoutKeys() {
+		if level.Tiles[idx].TileType == FLOOR && !centers[idx] {
+			candidates = append(candidates, idx)
+		}
+	}
+
+	for len(level.Keys) < count && len(candidates) > 0 {
+		i := rng.GetRandomInt(len(candidates))
+		idx := candidates[i]
+		candidates = append(candidates[:i], candidates[i+1:]...)
+		level.Keys = append(level.Keys, components.Position{X: idx % level.Width, Y: idx / level.Width})
+	}
+}
+
+// reachableWithoutKeys returns the indexes of the tiles that can be reached
+// from the center of the first room without going through a locked door.
+func (level *Level) reachableWithoutKeys() []int {
+	x, y := level.Rooms[0].Center()
+	start := level.GetIndexFromXY(x, y)
+	seen := map[int]bool{start: true}
+	reached := []int{start}
+
+	for i := 0; i < len(reached); i++ {
+		cx, cy := reached[i]%level.Width, reached[i]/level.Width
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			nx, ny := cx+d[0], cy+d[1]
+			if !level.InBounds(nx, ny) {
+				continue
+			}
+			n := level.GetIndexFromXY(nx, ny)
+			if !seen[n] && level.Tiles[n].IsPassable() {
+				seen[n] = true
+				reached = append(reached, n)
+			}
+		}
+	}
+	return reached
+}
@@ -0,0 +1,152 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+// corridorLevel returns two rooms joined by a corridor along y = 4. The
+// corridor meets the first room's wall at 6,4 and the second's at 12,4.
+func corridorLevel() Level {
+	level := Level{Width: 20, Height: 10}
+	level.Tiles = level.createTiles()
+	level.Rooms = []utils.Rect{utils.NewRect(1, 1, 5, 6), utils.NewRect(12, 1, 5, 6)}
+	for _, room := range level.Rooms {
+		level.createRoom(room)
+	}
+	level.createHorizontalTunnel(3, 14, 4)
+	return level
+}
+
+func TestIsDoorway(t *testing.T) {
+	level := corridorLevel()
+
+	tests := []struct {
+		name     string
+		x, y     int
+		expected bool
+	}{
+		{name: "where the corridor meets the first room", x: 6, y: 4, expected: true},
+		{name: "where the corridor meets the second room", x: 12, y: 4, expected: true},
+		{name: "middle of the corridor", x: 9, y: 4, expected: true},
+		{name: "inside a room", x: 3, y: 3, expected: false},
+		{name: "a wall", x: 6, y: 2, expected: false},
+		{name: "the level edge", x: 0, y: 4, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := level.isDoorway(tt.x, tt.y); result != tt.expected {
+				t.Errorf("isDoorway(%d, %d) = %v, expected %v", tt.x, tt.y, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlaceDoorsOnRoomWalls(t *testing.T) {
+	level := corridorLevel()
+	level.placeDoors(utils.NewSeededRNG(1))
+
+	for _, pos := range []components.Position{{X: 6, Y: 4}, {X: 12, Y: 4}} {
+		if tile := level.Tiles[level.GetIndexFromXY(pos.X, pos.Y)]; tile.TileType != DOOR {
+			t.Errorf("expected a door where the corridor meets a room at %d,%d", pos.X, pos.Y)
+		}
+	}
+	if tile := level.Tiles[level.GetIndexFromXY(9, 4)]; tile.TileType != FLOOR {
+		t.Error("expected no door in the middle of the corridor")
+	}
+}
+
+func TestDoorStates(t *testing.T) {
+	level := corridorLevel()
+	tile := level.Tiles[level.GetIndexFromXY(6, 4)]
+	tile.setDoorState(DoorLocked)
+
+	if level.OpenDoor(6, 4) {
+		t.Error("a locked door should not open")
+	}
+	if tile.IsPassable() {
+		t.Error("a locked door should be impassable")
+	}
+
+	if !level.UnlockDoor(6, 4) || tile.DoorState != DoorClosed {
+		t.Fatal("expected the door to unlock to closed")
+	}
+	if !tile.IsPassable() || !tile.Blocked || tile.MoveCost() <= 1 {
+		t.Error("a closed door should be passable at extra cost but blocked until opened")
+	}
+
+	if !level.OpenDoor(6, 4) || tile.DoorState != DoorOpen || tile.Blocked {
+		t.Fatal("expected the door to open and stop blocking")
+	}
+	if tile.MoveCost() != 1 {
+		t.Errorf("MoveCost() of an open door = %d, expected 1", tile.MoveCost())
+	}
+
+	tile.Blocked = true // Something is standing in the doorway
+	if level.CloseDoor(6, 4) {
+		t.Error("a door with something in it should not close")
+	}
+	tile.Blocked = false
+	if !level.CloseDoor(6, 4) || tile.DoorState != DoorClosed {
+		t.Error("expected the door to close")
+	}
+
+	if level.OpenDoor(3, 3) || level.CloseDoor(3, 3) || level.UnlockDoor(3, 3) {
+		t.Error("floor tiles are not doors")
+	}
+}
+
+func TestAStarThroughDoors(t *testing.T) {
+	level := corridorLevel()
+	level.Tiles[level.GetIndexFromXY(6, 4)].setDoorState(DoorClosed)
+	start := components.Position{X: 3, Y: 4}
+	end := components.Position{X: 14, Y: 4}
+
+	if path := (AStar{}).GetPath(level, &start, &end); len(path) == 0 {
+		t.Error("expected a path through a closed door")
+	}
+
+	level.Tiles[level.GetIndexFromXY(6, 4)].setDoorState(DoorLocked)
+	if path := (AStar{}).GetPath(level, &start, &end); path != nil {
+		t.Errorf("expected no path through a locked door, got %v", path)
+	}
+}
+
+func TestKeysAreReachable(t *testing.T) {
+	keys := 0
+	for seed := int64(1); seed <= 500; seed++ {
+		rng := utils.NewSeededRNG(seed)
+		level := Level{Width: 80, Height: 50, Depth: 1}
+		level.GenerateLevelTiles(RoomsGenerator{}, rng)
+		level.placeStairs(rng)
+		level.placeDoors(rng)
+
+		locked := 0
+		for _, tile := range level.Tiles {
+			if tile.TileType == DOOR && tile.DoorState == DoorLocked {
+				locked++
+			}
+		}
+		if len(level.Keys) != locked {
+			t.Fatalf("seed %d: %d keys for %d locked doors", seed, len(level.Keys), locked)
+		}
+		keys += len(level.Keys)
+
+		reachable := make(map[int]bool)
+		for _, idx := range level.reachableWithoutKeys() {
+			reachable[idx] = true
+		}
+		for _, key := range level.Keys {
+			idx := level.GetIndexFromXY(key.X, key.Y)
+			if !reachable[idx] || level.Tiles[idx].TileType != FLOOR {
+				t.Fatalf("seed %d: key at %+v is behind a locked door", seed, key)
+			}
+		}
+	}
+
+	if keys == 0 {
+		t.Error("expected some levels to have locked doors")
+	}
+}
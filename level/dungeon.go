@@ -1,7 +1,44 @@
 package level
 
+import (
+	"log"
+
+	"github.com/caustin/rrogue/utils"
+)
+
 // Dungeon is a container for all the levels that make up a particular dungeon in the world.
+// A dungeon is Depths levels deep, or has no bottom if Depths is 0, and its
+// first level is at FirstDepth. Levels are ordered by depth, so Levels[0] is
+// at FirstDepth.
+// Each dungeon names its own Generator so different dungeons can have
+// different layouts, and has its own set of Vaults to stamp into them and
+// Images to draw their tiles with.
 type Dungeon struct {
-	Name   string
-	Levels []Level
+	Name       string
+	Levels     []Level
+	Width      int
+	Height     int
+	FirstDepth int
+	Depths     int
+	Generator  GeneratorKind
+	Vaults     []Vault     `json:"-"`
+	Images     *TileImages `json:"-"`
+}
+
+// Contains reports whether depth is one of the dungeon's levels, generated
+// or not.
+func (d *Dungeon) Contains(depth int) bool {
+	return depth >= d.FirstDepth && (d.Depths == 0 || depth < d.FirstDepth+d.Depths)
+}
+
+// NewLevel generates the next level of the dungeon, sized to the dungeon's
+// dimensions and laid out by its kind of generator.
+func (d *Dungeon) NewLevel(rng utils.RNG) Level {
+	generator, err := NewGenerator(d.Generator)
+	if err != nil {
+		log.Fatalf("dungeon %s: %v", d.Name, err)
+	}
+	l := NewLevel(d.Width, d.Height, d.FirstDepth+len(d.Levels), generator, d.Images, rng, d.Vaults...)
+	d.Levels = append(d.Levels, l)
+	return l
 }
@@ -0,0 +1,39 @@
+package level
+
+import (
+	"fmt"
+
+	"github.com/caustin/rrogue/utils"
+)
+
+// Generator lays out a level. It is handed a level that is solid wall and
+// carves out the floor, appending every room it creates to level.Rooms. The
+// first room is where the player starts and the last holds the down stairs,
+// so generators must make sure every room can be reached.
+type Generator interface {
+	Generate(level *Level, rng utils.RNG)
+}
+
+// GeneratorKind names one of the generators. Dungeons record the kind of
+// generator they use rather than the generator itself, so it can be saved.
+type GeneratorKind string
+
+const (
+	RoomsLayout GeneratorKind = "rooms"
+	BSPLayout   GeneratorKind = "bsp"
+	CaveLayout  GeneratorKind = "cave"
+)
+
+// NewGenerator returns the generator of the given kind, with settings suited
+// to an 80x50 level.
+func NewGenerator(kind GeneratorKind) (Generator, error) {
+	switch kind {
+	case RoomsLayout:
+		return RoomsGenerator{}, nil
+	case BSPLayout:
+		return NewBSPGenerator(), nil
+	case CaveLayout:
+		return NewCaveGenerator(), nil
+	}
+	return nil, fmt.Errorf("unknown level generator %q", kind)
+}
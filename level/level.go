@@ -1,11 +1,12 @@
 package level
 
 import (
+	"github.com/caustin/rrogue/assets"
+	"github.com/caustin/rrogue/components"
 	"github.com/caustin/rrogue/config"
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/norendren/go-fov/fov"
 
 	"github.com/caustin/rrogue/utils"
@@ -13,79 +14,148 @@ import (
 
 type TileType int
 
-var floor *ebiten.Image = nil
-var wall *ebiten.Image = nil
-var levelHeight int = 0
-
 const (
 	WALL TileType = iota
 	FLOOR
+	STAIRS_DOWN
+	STAIRS_UP
+	DOOR
+)
+
+// DoorState is whether a DOOR tile can be seen and walked through.
+type DoorState int
+
+const (
+	DoorOpen DoorState = iota
+	DoorClosed
+	DoorLocked
 )
 
 // Level holds the tile information for a complete dungeon level.
+// Width and Height are the size of the level in tiles, independent of the window.
+// The tile images and the field of view aren't saved; Restore rebuilds them.
 type Level struct {
+	Width         int
+	Height        int
 	Tiles         []*MapTile
 	Rooms         []utils.Rect
-	PlayerVisible *fov.View
+	PlayerVisible *fov.View `json:"-"`
+	Depth         int
+	StairsDown    components.Position
+	StairsUp      components.Position
+	Keys          []components.Position // Where the keys to the level's locked doors lie
+	SpawnPoints   []SpawnPoint          // Where vaults want monsters and items
+	images        *TileImages
 }
 
-// MapTile is a single Tile on a given level
+// MapTile is a single Tile on a given level.
+// PixelX and PixelY are the tile's position within the whole level; the
+// camera decides where, if anywhere, it appears on screen. Its image follows
+// from its type and door state.
 type MapTile struct {
 	PixelX     int
 	PixelY     int
 	Blocked    bool
-	Image      *ebiten.Image
 	IsRevealed bool
 	TileType   TileType
+	DoorState  DoorState // Only meaningful for DOOR tiles
 }
 
-// NewLevel creates a new game level in a dungeon.
-func NewLevel() Level {
-	l := Level{}
-	loadTileImages()
-
-	rooms := make([]utils.Rect, 0)
-	l.Rooms = rooms
-	l.GenerateLevelTiles()
+// NewLevel creates a new game level of width x height tiles at the given depth
+// of a dungeon, laid out by the generator and drawn with images. Every random
+// choice is drawn from rng so the same seed always produces the same level.
+// Depth starts at 1 for the top level. Sometimes one of the vaults is stamped
+// into the level.
+func NewLevel(width int, height int, depth int, generator Generator, images *TileImages, rng utils.RNG, vaults ...Vault) Level {
+	l := Level{Width: width, Height: height, Depth: depth, images: images}
+
+	l.GenerateLevelTiles(generator, rng, vaults...)
+	l.placeStairs(rng)
+	l.placeDoors(rng)
 	l.PlayerVisible = fov.New()
 	return l
 }
 
-func loadTileImages() {
-	if floor != nil && wall != nil {
-		return
-	}
-	var err error
+// Restore rebuilds what isn't saved with a level: the images its tiles are
+// drawn with and an empty field of view.
+func (level *Level) Restore(images *TileImages) {
+	level.images = images
+	level.PlayerVisible = fov.New()
+}
+
+// TileImages are the images tiles are drawn with. Each game loads its own, so
+// a headless game never changes how another game's levels are drawn.
+type TileImages struct {
+	floor, wall, stairsDown, stairsUp, doorOpen, doorClosed, doorLocked *ebiten.Image
+}
 
-	floor, _, err = ebitenutil.NewImageFromFile("assets/floor.png")
-	if err != nil {
-		log.Fatal(err)
+// LoadTileImages loads the tile images through provider; assets.Null lets
+// levels be built headless.
+func LoadTileImages(provider assets.Provider) (*TileImages, error) {
+	images := &TileImages{}
+	for _, tile := range []struct {
+		path  string
+		image **ebiten.Image
+	}{
+		{"assets/floor.png", &images.floor},
+		{"assets/wall.png", &images.wall},
+		{"assets/stairs_down.png", &images.stairsDown},
+		{"assets/stairs_up.png", &images.stairsUp},
+		{"assets/door_open.png", &images.doorOpen},
+		{"assets/door_closed.png", &images.doorClosed},
+		{"assets/door_locked.png", &images.doorLocked},
+	} {
+		img, err := provider.Image(tile.path)
+		if err != nil {
+			return nil, err
+		}
+		*tile.image = img
 	}
+	return images, nil
+}
 
-	wall, _, err = ebitenutil.NewImageFromFile("assets/wall.png")
-	if err != nil {
-		log.Fatal(err)
+// forTile returns the image the tile is drawn with.
+func (images *TileImages) forTile(tile *MapTile) *ebiten.Image {
+	switch tile.TileType {
+	case FLOOR:
+		return images.floor
+	case STAIRS_DOWN:
+		return images.stairsDown
+	case STAIRS_UP:
+		return images.stairsUp
+	case DOOR:
+		switch tile.DoorState {
+		case DoorOpen:
+			return images.doorOpen
+		case DoorClosed:
+			return images.doorClosed
+		default:
+			return images.doorLocked
+		}
+	default:
+		return images.wall
 	}
 }
 
-// DrawLevel draws the level onto the screen.
-func (level *Level) DrawLevel(screen *ebiten.Image, gd config.GameData) {
-	for x := 0; x < gd.ScreenWidth; x++ {
-		for y := 0; y < levelHeight; y++ {
+// DrawLevel draws the part of the level inside the camera's view onto the screen.
+func (level *Level) DrawLevel(screen *ebiten.Image, gd config.GameData, camera *Camera) {
+	for x := camera.X; x < min(camera.X+camera.Width, level.Width); x++ {
+		for y := camera.Y; y < min(camera.Y+camera.Height, level.Height); y++ {
 			idx := level.GetIndexFromXY(x, y)
 			tile := level.Tiles[idx]
+			screenX, screenY := camera.ToScreen(x, y, gd)
 			isVis := level.PlayerVisible.IsVisible(x, y)
 			if isVis {
 				op := utils.GetDrawOptions()
-				op.GeoM.Translate(float64(tile.PixelX), float64(tile.PixelY))
-				screen.DrawImage(tile.Image, op)
+				op.GeoM.Translate(screenX, screenY)
+				screen.DrawImage(level.images.forTile(tile), op)
 				utils.PutDrawOptions(op)
 				level.Tiles[idx].IsRevealed = true
 			} else if tile.IsRevealed == true {
 				op := utils.GetDrawOptions()
-				op.GeoM.Translate(float64(tile.PixelX), float64(tile.PixelY))
+				op.GeoM.Translate(screenX, screenY)
 				op.ColorM.Translate(100, 100, 100, 0.35)
-				screen.DrawImage(tile.Image, op)
+				screen.DrawImage(level.images.forTile(tile), op)
 				utils.PutDrawOptions(op)
 			}
 		}
@@ -96,27 +166,60 @@ func (level *Level) DrawLevel(screen *ebiten.Image, gd config.GameData) {
 // GetIndexFromXY gets the index of the map array from a given X,Y TILE coordinate.
 // This coordinate is logical tiles, not pixels.
 func (level *Level) GetIndexFromXY(x int, y int) int {
-	gd := config.NewGameData()
-	return (y * gd.ScreenWidth) + x
+	return (y * level.Width) + x
 }
 
-// GenerateLevelTiles creates a new Dungeon Level Map.
-func (level *Level) GenerateLevelTiles() {
+// maxGenerationAttempts is how many layouts GenerateLevelTiles asks the
+// generator for before it repairs the last one itself.
+const maxGenerationAttempts = 5
+
+// GenerateLevelTiles creates a new Dungeon Level Map, starting from solid wall
+// and letting the generator carve out the rooms and corridors. One of the
+// vaults, if any are given, may then be stamped into the remaining solid rock.
+// Every walkable tile must be reachable from the start room, so a layout that
+// is not fully connected is thrown away and generated again, and if that keeps
+// failing the unreachable regions are tunnelled to.
+func (level *Level) GenerateLevelTiles(generator Generator, rng utils.RNG, vaults ...Vault) {
+	for attempt := 1; ; attempt++ {
+		level.Tiles = level.createTiles()
+		level.Rooms = make([]utils.Rect, 0)
+		level.SpawnPoints = make([]SpawnPoint, 0)
+		generator.Generate(level, rng)
+		level.placeVault(vaults, rng)
+
+		report := level.CheckConnectivity()
+		if report.Connected() {
+			return
+		}
+		if attempt == maxGenerationAttempts {
+			log.Printf("Level %d: repairing %d unreachable regions after %d attempts", level.Depth, len(report.Unreachable), attempt)
+			level.RepairConnectivity()
+			return
+		}
+	}
+}
+
+// RoomsGenerator places randomly sized rooms at random positions and joins
+// each new room to the previous one with an L-shaped tunnel.
+type RoomsGenerator struct{}
+
+// Generate carves random rooms and tunnels into the level.
+func (g RoomsGenerator) Generate(level *Level, rng utils.RNG) {
 	MIN_SIZE := 6
 	MAX_SIZE := 10
 	MAX_ROOMS := 30
 
-	gd := config.NewGameData()
-	levelHeight = gd.ScreenHeight - gd.UIHeight
-	tiles := level.createTiles()
-	level.Tiles = tiles
 	contains_rooms := false
 
 	for idx := 0; idx < MAX_ROOMS; idx++ {
-		w := utils.GetRandomBetween(MIN_SIZE, MAX_SIZE)
-		h := utils.GetRandomBetween(MIN_SIZE, MAX_SIZE)
-		x := utils.GetDiceRoll(gd.ScreenWidth - w - 1)
-		y := utils.GetDiceRoll(levelHeight - h - 1)
+		w := rng.GetRandomBetween(MIN_SIZE, MAX_SIZE)
+		h := rng.GetRandomBetween(MIN_SIZE, MAX_SIZE)
+		if level.Width-w-1 < 1 || level.Height-h-1 < 1 {
+			// The room can't fit inside the level's outer wall
+			continue
+		}
+		x := rng.GetDiceRoll(level.Width - w - 1)
+		y := rng.GetDiceRoll(level.Height - h - 1)
 		new_room := utils.NewRect(x, y, w, h)
 
 		okToAdd := true
@@ -132,7 +235,7 @@ func (level *Level) GenerateLevelTiles() {
 			if contains_rooms {
 				newX, newY := new_room.Center()
 				prevX, prevY := level.Rooms[len(level.Rooms)-1].Center()
-				coinflip := utils.GetDiceRoll(2)
+				coinflip := rng.GetDiceRoll(2)
 				if coinflip == 2 {
 					level.createHorizontalTunnel(prevX, newX, prevY)
 					level.createVerticalTunnel(prevY, newY, newX)
@@ -152,26 +255,22 @@ func (level *Level) GenerateLevelTiles() {
 }
 
 func (level *Level) createHorizontalTunnel(x1 int, x2 int, y int) {
-	gd := config.NewGameData()
 	for x := min(x1, x2); x < max(x1, x2)+1; x++ {
-		index := level.GetIndexFromXY(x, y)
-		if index > 0 && index < gd.ScreenWidth*levelHeight {
+		if level.InBounds(x, y) {
+			index := level.GetIndexFromXY(x, y)
 			level.Tiles[index].Blocked = false
 			level.Tiles[index].TileType = FLOOR
-			level.Tiles[index].Image = floor
 
 		}
 	}
 }
 
 func (level *Level) createVerticalTunnel(y1 int, y2 int, x int) {
-	gd := config.NewGameData()
 	for y := min(y1, y2); y < max(y1, y2)+1; y++ {
-		index := level.GetIndexFromXY(x, y)
-		if index > 0 && index < gd.ScreenWidth*levelHeight {
+		if level.InBounds(x, y) {
+			index := level.GetIndexFromXY(x, y)
 			level.Tiles[index].Blocked = false
 			level.Tiles[index].TileType = FLOOR
-			level.Tiles[index].Image = floor
 
 		}
 	}
@@ -180,16 +279,15 @@ func (level *Level) createVerticalTunnel(y1 int, y2 int, x int) {
 // createTiles creates a map of all walls as a baseline for carving out a level.
 func (level *Level) createTiles() []*MapTile {
 	gd := config.NewGameData()
-	tiles := make([]*MapTile, levelHeight*gd.ScreenWidth)
+	tiles := make([]*MapTile, level.Height*level.Width)
 	index := 0
-	for x := 0; x < gd.ScreenWidth; x++ {
-		for y := 0; y < levelHeight; y++ {
+	for x := 0; x < level.Width; x++ {
+		for y := 0; y < level.Height; y++ {
 			index = level.GetIndexFromXY(x, y)
 			tile := MapTile{
 				PixelX:     x * gd.TileWidth,
 				PixelY:     y * gd.TileHeight,
 				Blocked:    true,
-				Image:      wall,
 				IsRevealed: false,
 				TileType:   WALL,
 			}
@@ -200,27 +298,98 @@ func (level *Level) createTiles() []*MapTile {
 	return tiles
 }
 
+// placeStairs puts the down stairs in the last room and, below the first
+// level, the up stairs in the first room where the player arrives.
+// The stairs are kept off the room centers, where monsters are spawned, and
+// off each other when a level has only one room.
+func (level *Level) placeStairs(rng utils.RNG) {
+	if len(level.Rooms) == 0 {
+		return
+	}
+
+	last := level.Rooms[len(level.Rooms)-1]
+	if pos, ok := level.stairsPosition(last, rng); ok {
+		level.StairsDown = pos
+		level.setTile(level.StairsDown, STAIRS_DOWN)
+	}
+
+	if level.Depth > 1 {
+		first := level.Rooms[0]
+		if pos, ok := level.stairsPosition(first, rng); ok {
+			level.StairsUp = pos
+			level.setTile(level.StairsUp, STAIRS_UP)
+		}
+	}
+}
+
+// stairsPosition picks a random free floor tile inside the room that is not
+// its center. Stairs already placed aren't floor, so they are never picked. A
+// room too small to keep off its center gets the stairs there instead, and ok
+// is false if not even the center is free.
+func (level *Level) stairsPosition(room utils.Rect, rng utils.RNG) (pos components.Position, ok bool) {
+	cx, cy := room.Center()
+	center := components.Position{X: cx, Y: cy}
+	free := level.FreeTiles(room)
+	candidates := make([]components.Position, 0, len(free))
+	for _, p := range free {
+		if p != center {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		// Only the center is left, if even that is free
+		return center, len(free) > 0
+	}
+	return candidates[rng.GetDiceRoll(len(candidates))-1], true
+}
+
+func (level *Level) setTile(pos components.Position, tileType TileType) {
+	tile := level.Tiles[level.GetIndexFromXY(pos.X, pos.Y)]
+	tile.TileType = tileType
+	tile.Blocked = false
+}
+
 func (level *Level) createRoom(room utils.Rect) {
 	for y := room.Y1 + 1; y < room.Y2; y++ {
 		for x := room.X1 + 1; x < room.X2; x++ {
 			index := level.GetIndexFromXY(x, y)
 			level.Tiles[index].Blocked = false
 			level.Tiles[index].TileType = FLOOR
-			level.Tiles[index].Image = floor
 		}
 	}
 }
+
+// FreeTiles returns the floor tiles inside room that nothing is standing on,
+// row by row.
+func (level Level) FreeTiles(room utils.Rect) []components.Position {
+	free := make([]components.Position, 0)
+	for y := room.Y1 + 1; y < room.Y2; y++ {
+		for x := room.X1 + 1; x < room.X2; x++ {
+			if !level.InBounds(x, y) {
+				continue
+			}
+			tile := level.Tiles[level.GetIndexFromXY(x, y)]
+			if tile.TileType == FLOOR && !tile.Blocked {
+				free = append(free, components.Position{X: x, Y: y})
+			}
+		}
+	}
+	return free
+}
+
+// InBounds reports whether the X,Y tile coordinate lies inside the level.
 func (level Level) InBounds(x, y int) bool {
-	gd := config.NewGameData()
-	if x < 0 || x > gd.ScreenWidth || y < 0 || y > levelHeight {
+	if x < 0 || x >= level.Width || y < 0 || y >= level.Height {
 		return false
 	}
 	return true
 }
 
+// IsOpaque reports whether the tile blocks line of sight. Walls always do and
+// doors do unless they are open.
 func (level Level) IsOpaque(x, y int) bool {
-	idx := level.GetIndexFromXY(x, y)
-	return level.Tiles[idx].TileType == WALL
+	tile := level.Tiles[level.GetIndexFromXY(x, y)]
+	return tile.TileType == WALL || (tile.TileType == DOOR && tile.DoorState != DoorOpen)
 }
 
 // Max returns the larger of x or y.
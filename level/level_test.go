@@ -2,11 +2,12 @@ package level
 
 import (
 	"github.com/caustin/rrogue/config"
+	"github.com/caustin/rrogue/utils"
 	"testing"
 )
 
 func TestGetIndexFromXY(t *testing.T) {
-	level := Level{}
+	level := Level{Width: 80, Height: 50}
 
 	tests := []struct {
 		name     string
@@ -29,7 +30,7 @@ func TestGetIndexFromXY(t *testing.T) {
 			name:     "second row start (0,1)",
 			x:        0,
 			y:        1,
-			expected: 80, // y * ScreenWidth = 1 * 80
+			expected: 80, // y * Width = 1 * 80
 		},
 		{
 			name:     "middle position (10,5)",
@@ -49,10 +50,18 @@ func TestGetIndexFromXY(t *testing.T) {
 	}
 }
 
+func TestGetIndexFromXYUsesLevelWidth(t *testing.T) {
+	level := Level{Width: 200, Height: 200}
+
+	if result := level.GetIndexFromXY(10, 5); result != 1010 {
+		t.Errorf("GetIndexFromXY(10, 5) on a 200 wide level = %d, expected 1010", result)
+	}
+}
+
 func TestInBounds(t *testing.T) {
-	level := Level{}
 	gd := config.NewGameData()
-	levelHeight = gd.ScreenHeight - gd.UIHeight // Set global variable
+	level := Level{Width: gd.LevelWidth, Height: gd.LevelHeight}
+	levelHeight := level.Height
 
 	tests := []struct {
 		name     string
@@ -67,7 +76,7 @@ func TestInBounds(t *testing.T) {
 		},
 		{
 			name:     "valid position near edge",
-			x:        gd.ScreenWidth - 1,
+			x:        level.Width - 1,
 			y:        levelHeight - 1,
 			expected: true,
 		},
@@ -84,20 +93,20 @@ func TestInBounds(t *testing.T) {
 			expected: false,
 		},
 		{
-			name:     "x at boundary (valid)",
-			x:        gd.ScreenWidth,
+			name:     "x at boundary (invalid)",
+			x:        level.Width,
 			y:        10,
-			expected: true,
+			expected: false,
 		},
 		{
-			name:     "y at boundary (valid)",
+			name:     "y at boundary (invalid)",
 			x:        10,
 			y:        levelHeight,
-			expected: true,
+			expected: false,
 		},
 		{
 			name:     "x too large",
-			x:        gd.ScreenWidth + 1,
+			x:        level.Width + 1,
 			y:        10,
 			expected: false,
 		},
@@ -120,12 +129,11 @@ func TestInBounds(t *testing.T) {
 }
 
 func TestIsOpaque(t *testing.T) {
-	level := Level{}
 	gd := config.NewGameData()
-	levelHeight := gd.ScreenHeight - gd.UIHeight
+	level := Level{Width: gd.LevelWidth, Height: gd.LevelHeight}
 
 	// Create tiles for testing
-	tiles := make([]*MapTile, levelHeight*gd.ScreenWidth)
+	tiles := make([]*MapTile, level.Height*level.Width)
 	for i := range tiles {
 		tiles[i] = &MapTile{
 			TileType: WALL, // Default to wall (opaque)
@@ -137,6 +145,12 @@ func TestIsOpaque(t *testing.T) {
 	floorIndex := level.GetIndexFromXY(5, 5)
 	level.Tiles[floorIndex].TileType = FLOOR
 
+	// And some to doors in each state
+	for x, state := range map[int]DoorState{6: DoorOpen, 7: DoorClosed, 8: DoorLocked} {
+		level.Tiles[level.GetIndexFromXY(x, 5)].TileType = DOOR
+		level.Tiles[level.GetIndexFromXY(x, 5)].DoorState = state
+	}
+
 	tests := []struct {
 		name     string
 		x, y     int
@@ -160,6 +174,24 @@ func TestIsOpaque(t *testing.T) {
 			y:        10,
 			expected: true,
 		},
+		{
+			name:     "open door is not opaque",
+			x:        6,
+			y:        5,
+			expected: false,
+		},
+		{
+			name:     "closed door is opaque",
+			x:        7,
+			y:        5,
+			expected: true,
+		},
+		{
+			name:     "locked door is opaque",
+			x:        8,
+			y:        5,
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -233,12 +265,11 @@ func TestMinMax(t *testing.T) {
 }
 
 func TestCreateRoom(t *testing.T) {
-	level := Level{}
 	gd := config.NewGameData()
-	levelHeight := gd.ScreenHeight - gd.UIHeight
+	level := Level{Width: gd.LevelWidth, Height: gd.LevelHeight}
 
 	// Initialize tiles as walls
-	tiles := make([]*MapTile, levelHeight*gd.ScreenWidth)
+	tiles := make([]*MapTile, level.Height*level.Width)
 	for i := range tiles {
 		tiles[i] = &MapTile{
 			Blocked:  true,
@@ -248,7 +279,7 @@ func TestCreateRoom(t *testing.T) {
 	level.Tiles = tiles
 
 	// Create a 3x3 room at position (5,5)
-	room := NewRect(5, 5, 3, 3)
+	room := utils.NewRect(5, 5, 3, 3)
 	level.createRoom(room)
 
 	// Check that interior tiles are floors and not blocked
@@ -287,3 +318,177 @@ func TestCreateRoom(t *testing.T) {
 		}
 	}
 }
+
+func TestFreeTiles(t *testing.T) {
+	level := Level{Width: 10, Height: 10}
+	level.Tiles = level.createTiles()
+	room := utils.NewRect(1, 1, 4, 4)
+	level.createRoom(room)
+
+	if free := level.FreeTiles(room); len(free) != 9 {
+		t.Fatalf("FreeTiles found %d tiles in a 3x3 room, expected 9", len(free))
+	}
+
+	level.Tiles[level.GetIndexFromXY(2, 2)].Blocked = true
+	level.Tiles[level.GetIndexFromXY(3, 3)].TileType = STAIRS_DOWN
+	free := level.FreeTiles(room)
+	if len(free) != 7 {
+		t.Fatalf("FreeTiles found %d tiles, expected 7", len(free))
+	}
+	for _, pos := range free {
+		if (pos.X == 2 && pos.Y == 2) || (pos.X == 3 && pos.Y == 3) {
+			t.Errorf("FreeTiles included %+v", pos)
+		}
+	}
+}
+
+func TestPlaceStairs(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    int
+		expectUp bool
+	}{
+		{
+			name:     "first level has only down stairs",
+			depth:    1,
+			expectUp: false,
+		},
+		{
+			name:     "deeper level has both stairs",
+			depth:    3,
+			expectUp: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := Level{Width: 80, Height: 50, Depth: tt.depth}
+			level.GenerateLevelTiles(RoomsGenerator{}, utils.NewSeededRNG(int64(tt.depth)))
+			level.placeStairs(utils.NewSeededRNG(int64(tt.depth)))
+
+			downCount, upCount := 0, 0
+			for _, tile := range level.Tiles {
+				switch tile.TileType {
+				case STAIRS_DOWN:
+					downCount++
+				case STAIRS_UP:
+					upCount++
+				}
+			}
+
+			if downCount != 1 {
+				t.Errorf("Expected 1 down stairs, found %d", downCount)
+			}
+
+			down := level.Tiles[level.GetIndexFromXY(level.StairsDown.X, level.StairsDown.Y)]
+			if down.TileType != STAIRS_DOWN || down.Blocked {
+				t.Errorf("StairsDown (%d, %d) is not an open down stairs tile", level.StairsDown.X, level.StairsDown.Y)
+			}
+
+			if tt.expectUp {
+				if upCount != 1 {
+					t.Errorf("Expected 1 up stairs, found %d", upCount)
+				}
+				up := level.Tiles[level.GetIndexFromXY(level.StairsUp.X, level.StairsUp.Y)]
+				if up.TileType != STAIRS_UP || up.Blocked {
+					t.Errorf("StairsUp (%d, %d) is not an open up stairs tile", level.StairsUp.X, level.StairsUp.Y)
+				}
+			} else if upCount != 0 {
+				t.Errorf("Expected no up stairs on depth %d, found %d", tt.depth, upCount)
+			}
+
+			// Stairs stay off room centers, which are used for spawning
+			for _, room := range level.Rooms {
+				cx, cy := room.Center()
+				if tile := level.Tiles[level.GetIndexFromXY(cx, cy)]; tile.TileType == STAIRS_DOWN || tile.TileType == STAIRS_UP {
+					t.Errorf("Stairs placed on room center (%d, %d)", cx, cy)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceStairsInOneRoomKeepsThemApart(t *testing.T) {
+	// A cramped cave can come out as a single room holding both stairs
+	for seed := int64(1); seed <= 50; seed++ {
+		level := Level{Width: 8, Height: 8, Depth: 2}
+		level.Tiles = level.createTiles()
+		level.Rooms = []utils.Rect{utils.NewRect(1, 1, 4, 4)}
+		level.createRoom(level.Rooms[0])
+		level.placeStairs(utils.NewSeededRNG(seed))
+
+		if level.StairsUp == level.StairsDown {
+			t.Fatalf("seed %d: both stairs at %v", seed, level.StairsDown)
+		}
+		if tile := level.Tiles[level.GetIndexFromXY(level.StairsDown.X, level.StairsDown.Y)]; tile.TileType != STAIRS_DOWN {
+			t.Fatalf("seed %d: down stairs at %v were overwritten", seed, level.StairsDown)
+		}
+	}
+}
+
+func TestPlaceStairsInTinyRooms(t *testing.T) {
+	tests := []struct {
+		name       string
+		room       utils.Rect
+		downStairs bool
+		upStairs   bool
+	}{
+		{name: "only a center tile", room: utils.NewRect(1, 1, 2, 2), downStairs: true},
+		{name: "a center tile and one more", room: utils.NewRect(1, 1, 3, 2), downStairs: true, upStairs: true},
+		{name: "no floor at all", room: utils.NewRect(1, 1, 1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := Level{Width: 8, Height: 8, Depth: 2}
+			level.Tiles = level.createTiles()
+			level.Rooms = []utils.Rect{tt.room}
+			level.createRoom(tt.room)
+			level.placeStairs(utils.NewSeededRNG(1))
+
+			var down, up int
+			for _, tile := range level.Tiles {
+				switch tile.TileType {
+				case STAIRS_DOWN:
+					down++
+				case STAIRS_UP:
+					up++
+				}
+			}
+			if (down == 1) != tt.downStairs || (up == 1) != tt.upStairs || down > 1 || up > 1 {
+				t.Errorf("placed %d down and %d up stairs, expected down %v and up %v", down, up, tt.downStairs, tt.upStairs)
+			}
+		})
+	}
+}
+
+func TestGenerateLevelTilesUsesLevelSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+	}{
+		{name: "larger than the window", width: 200, height: 200},
+		{name: "smaller than the window", width: 40, height: 30},
+		{name: "wide and short", width: 120, height: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := Level{Width: tt.width, Height: tt.height}
+			level.GenerateLevelTiles(RoomsGenerator{}, utils.NewSeededRNG(1))
+
+			if len(level.Tiles) != tt.width*tt.height {
+				t.Fatalf("Expected %d tiles, got %d", tt.width*tt.height, len(level.Tiles))
+			}
+
+			if len(level.Rooms) == 0 {
+				t.Fatal("Expected at least one room")
+			}
+
+			for _, room := range level.Rooms {
+				if room.X1 < 0 || room.Y1 < 0 || room.X2 >= tt.width || room.Y2 >= tt.height {
+					t.Errorf("Room %+v does not fit inside a %dx%d level", room, tt.width, tt.height)
+				}
+			}
+		})
+	}
+}
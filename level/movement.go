@@ -0,0 +1,47 @@
+package level
+
+import "github.com/caustin/rrogue/components"
+
+// CardinalDirections are the steps for 4-way movement: up, down, left and right.
+var CardinalDirections = [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}}
+
+// DiagonalDirections are the extra steps for 8-way movement.
+var DiagonalDirections = [][2]int{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+
+// Directions returns the steps allowed for 4-way or, if diagonal is true,
+// 8-way movement.
+func Directions(diagonal bool) [][2]int {
+	if !diagonal {
+		return CardinalDirections
+	}
+	return append(append([][2]int{}, CardinalDirections...), DiagonalDirections...)
+}
+
+// CanStep reports whether the terrain allows a step of dx,dy from x,y,
+// ignoring whatever may be standing on the destination. Diagonal steps may
+// not cut the corner of a wall, and doors can only be passed straight
+// through.
+func (level Level) CanStep(x int, y int, dx int, dy int) bool {
+	nx, ny := x+dx, y+dy
+	if !level.InBounds(nx, ny) || !level.Tiles[level.GetIndexFromXY(nx, ny)].IsPassable() {
+		return false
+	}
+	if dx == 0 || dy == 0 {
+		return true
+	}
+
+	if level.Tiles[level.GetIndexFromXY(x, y)].TileType == DOOR || level.Tiles[level.GetIndexFromXY(nx, ny)].TileType == DOOR {
+		return false
+	}
+	return level.Tiles[level.GetIndexFromXY(nx, y)].TileType != WALL &&
+		level.Tiles[level.GetIndexFromXY(x, ny)].TileType != WALL
+}
+
+// InMeleeRange reports whether something at from can attack something at to:
+// they are one step apart and, for a diagonal step, CanStep allows it.
+func (level Level) InMeleeRange(from *components.Position, to *components.Position, diagonal bool) bool {
+	if from.GetManhattanDistance(to) == 1 {
+		return true
+	}
+	return diagonal && from.GetChebyshevDistance(to) == 1 && level.CanStep(from.X, from.Y, to.X-from.X, to.Y-from.Y)
+}
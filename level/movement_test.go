@@ -0,0 +1,114 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+// openLevel returns a level that is one big room with a single wall tile
+// at 5,5 and a door at 8,5.
+func openLevel() Level {
+	level := Level{Width: 12, Height: 12}
+	level.Tiles = level.createTiles()
+	level.Rooms = []utils.Rect{utils.NewRect(0, 0, 11, 11)}
+	level.createRoom(level.Rooms[0])
+	level.Tiles[level.GetIndexFromXY(5, 5)].TileType = WALL
+	level.Tiles[level.GetIndexFromXY(8, 5)].setDoorState(DoorOpen)
+	return level
+}
+
+func TestCanStep(t *testing.T) {
+	level := openLevel()
+
+	tests := []struct {
+		name     string
+		x, y     int
+		dx, dy   int
+		expected bool
+	}{
+		{name: "cardinal onto floor", x: 2, y: 2, dx: 1, dy: 0, expected: true},
+		{name: "cardinal into a wall", x: 4, y: 5, dx: 1, dy: 0, expected: false},
+		{name: "diagonal across open floor", x: 2, y: 2, dx: 1, dy: 1, expected: true},
+		{name: "diagonal cutting a corner", x: 4, y: 5, dx: 1, dy: 1, expected: false},
+		{name: "diagonal cutting the other corner", x: 5, y: 4, dx: 1, dy: 1, expected: false},
+		{name: "diagonal into a doorway", x: 7, y: 4, dx: 1, dy: 1, expected: false},
+		{name: "diagonal out of a doorway", x: 8, y: 5, dx: 1, dy: -1, expected: false},
+		{name: "straight through a doorway", x: 7, y: 5, dx: 1, dy: 0, expected: true},
+		{name: "out of bounds", x: 1, y: 1, dx: -1, dy: -1, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := level.CanStep(tt.x, tt.y, tt.dx, tt.dy); result != tt.expected {
+				t.Errorf("CanStep(%d, %d, %d, %d) = %v, expected %v", tt.x, tt.y, tt.dx, tt.dy, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInMeleeRange(t *testing.T) {
+	level := openLevel()
+	from := components.Position{X: 2, Y: 2}
+	side := components.Position{X: 3, Y: 2}
+	diagonal := components.Position{X: 3, Y: 3}
+	corner := components.Position{X: 4, Y: 5}
+	aroundCorner := components.Position{X: 5, Y: 6}
+
+	if !level.InMeleeRange(&from, &side, false) {
+		t.Error("orthogonal neighbours should always be in range")
+	}
+	if level.InMeleeRange(&from, &diagonal, false) {
+		t.Error("diagonal neighbours should be out of range with 4-way movement")
+	}
+	if !level.InMeleeRange(&from, &diagonal, true) {
+		t.Error("diagonal neighbours should be in range with 8-way movement")
+	}
+	if level.InMeleeRange(&corner, &aroundCorner, true) {
+		t.Error("attacks should not reach around a corner")
+	}
+}
+
+func TestAStarDiagonal(t *testing.T) {
+	level := openLevel()
+	start := components.Position{X: 1, Y: 1}
+	end := components.Position{X: 4, Y: 9}
+
+	if path := (AStar{}).GetPath(level, &start, &end); len(path) != 12 {
+		t.Errorf("4-way path has %d positions, expected 12", len(path))
+	}
+
+	path := AStar{Diagonal: true}.GetPath(level, &start, &end)
+	if len(path) != 9 {
+		t.Fatalf("8-way path has %d positions, expected 9", len(path))
+	}
+	for i := 1; i < len(path); i++ {
+		d := [2]int{path[i].X - path[i-1].X, path[i].Y - path[i-1].Y}
+		if !level.CanStep(path[i-1].X, path[i-1].Y, d[0], d[1]) {
+			t.Errorf("step %d from %+v to %+v breaks the corner rules", i, path[i-1], path[i])
+		}
+	}
+}
+
+func TestAStarDiagonalAroundWall(t *testing.T) {
+	level := openLevel()
+	start := components.Position{X: 1, Y: 1}
+	end := components.Position{X: 9, Y: 9}
+
+	// The straight diagonal runs into the wall at 5,5 and going round it
+	// without cutting a corner takes two extra steps
+	if path := (AStar{Diagonal: true}).GetPath(level, &start, &end); len(path) != 11 {
+		t.Errorf("8-way path has %d positions, expected 11", len(path))
+	}
+}
+
+func TestAStarDiagonalAroundCorner(t *testing.T) {
+	level := openLevel()
+	start := components.Position{X: 4, Y: 5}
+	end := components.Position{X: 5, Y: 6}
+
+	path := AStar{Diagonal: true}.GetPath(level, &start, &end)
+	if len(path) != 3 {
+		t.Errorf("expected the path to go round the corner in 2 steps, got %v", path)
+	}
+}
@@ -0,0 +1,242 @@
+package level
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/utils"
+)
+
+// vaultChance is how often a level gets a vault: one level in vaultChance.
+const vaultChance = 2
+
+// vaultPlacementTries is how many random spots are tried when looking for
+// enough free space to stamp a vault.
+const vaultPlacementTries = 50
+
+// SpawnKind is what a spawn point marks the place of.
+type SpawnKind int
+
+const (
+	SpawnMonster SpawnKind = iota
+	SpawnItem
+)
+
+// SpawnPoint is a spot that a vault asks the world to put something on.
+type SpawnPoint struct {
+	Kind     SpawnKind
+	Position components.Position
+}
+
+// Vault is a hand drawn room, such as a treasure vault or a shrine, that is
+// stamped into generated levels. Vaults are drawn as text, one character per
+// tile:
+//
+//	#  wall
+//	.  floor
+//	+  closed door
+//	=  locked door
+//	M  floor with a monster spawn point
+//	I  floor with an item spawn point
+//
+// Floor or doors on the outer edge of the drawing are the vault's entrances
+// and are tunnelled to the rest of the level.
+type Vault struct {
+	Name   string
+	Width  int
+	Height int
+	rows   []string
+}
+
+// ParseVault reads a vault drawing. Blank lines and lines starting with ';'
+// are ignored, and every row must be the same width.
+func ParseVault(name string, data string) (Vault, error) {
+	v := Vault{Name: name}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if v.Width != 0 && len(line) != v.Width {
+			return Vault{}, fmt.Errorf("vault %s: row %d is %d wide, expected %d", name, len(v.rows)+1, len(line), v.Width)
+		}
+		for _, ch := range line {
+			if !strings.ContainsRune("#.+=MI", ch) {
+				return Vault{}, fmt.Errorf("vault %s: unknown tile %q", name, ch)
+			}
+		}
+		v.Width = len(line)
+		v.rows = append(v.rows, line)
+	}
+	v.Height = len(v.rows)
+
+	if v.Height == 0 {
+		return Vault{}, fmt.Errorf("vault %s is empty", name)
+	}
+	if len(v.entrances()) == 0 {
+		return Vault{}, fmt.Errorf("vault %s has no entrance on its edge", name)
+	}
+	return v, nil
+}
+
+// LoadVaults parses every .txt file in dir, in file name order.
+func LoadVaults(dir string) ([]Vault, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	vaults := make([]Vault, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		v, err := ParseVault(strings.TrimSuffix(filepath.Base(path), ".txt"), string(data))
+		if err != nil {
+			return nil, err
+		}
+		vaults = append(vaults, v)
+	}
+	return vaults, nil
+}
+
+// entrances returns the walkable tiles on the edge of the vault, relative to
+// its top left corner.
+func (v Vault) entrances() []components.Position {
+	entrances := make([]components.Position, 0)
+	for y, row := range v.rows {
+		for x, ch := range row {
+			edge := x == 0 || y == 0 || x == v.Width-1 || y == v.Height-1
+			if edge && ch != '#' {
+				entrances = append(entrances, components.Position{X: x, Y: y})
+			}
+		}
+	}
+	return entrances
+}
+
+// placeVault picks one of the vaults, now and then, and stamps it into the
+// level where there is nothing but solid wall.
+func (level *Level) placeVault(vaults []Vault, rng utils.RNG) {
+	if len(vaults) == 0 || rng.GetDiceRoll(vaultChance) != 1 {
+		return
+	}
+
+	v := vaults[rng.GetRandomInt(len(vaults))]
+	if v.Width+4 > level.Width || v.Height+4 > level.Height {
+		return
+	}
+	for try := 0; try < vaultPlacementTries; try++ {
+		// Leave a wall between the vault and both the level edge and anything carved
+		x := rng.GetRandomBetween(2, level.Width-v.Width-2)
+		y := rng.GetRandomBetween(2, level.Height-v.Height-2)
+		if level.isSolid(utils.NewRect(x-1, y-1, v.Width+1, v.Height+1)) {
+			level.stampVault(v, x, y)
+			return
+		}
+	}
+}
+
+// isSolid reports whether every tile of the area, edges included, is an in
+// bounds wall.
+func (level *Level) isSolid(area utils.Rect) bool {
+	for y := area.Y1; y <= area.Y2; y++ {
+		for x := area.X1; x <= area.X2; x++ {
+			if !level.InBounds(x, y) || level.Tiles[level.GetIndexFromXY(x, y)].TileType != WALL {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// stampVault copies the vault into the level with its top left corner at x,y,
+// records its spawn points and tunnels from each entrance to the nearest
+// walkable tile outside the vault.
+func (level *Level) stampVault(v Vault, x int, y int) {
+	for vy, row := range v.rows {
+		for vx, ch := range row {
+			pos := components.Position{X: x + vx, Y: y + vy}
+			tile := level.Tiles[level.GetIndexFromXY(pos.X, pos.Y)]
+			switch ch {
+			case '#':
+				continue
+			case '+':
+				tile.setDoorState(DoorClosed)
+				continue
+			case '=':
+				tile.setDoorState(DoorLocked)
+				continue
+			case 'M':
+				level.SpawnPoints = append(level.SpawnPoints, SpawnPoint{Kind: SpawnMonster, Position: pos})
+			case 'I':
+				level.SpawnPoints = append(level.SpawnPoints, SpawnPoint{Kind: SpawnItem, Position: pos})
+			}
+			tile.Blocked = false
+			tile.TileType = FLOOR
+		}
+	}
+
+	area := utils.NewRect(x, y, v.Width-1, v.Height-1)
+	for _, e := range v.entrances() {
+		outside := components.Position{X: x + e.X, Y: y + e.Y}
+		switch {
+		case e.X == 0:
+			outside.X--
+		case e.X == v.Width-1:
+			outside.X++
+		case e.Y == 0:
+			outside.Y--
+		default:
+			outside.Y++
+		}
+		level.tunnelOut(outside, area)
+	}
+}
+
+// tunnelOut digs the shortest path from start to a walkable tile outside the
+// area, never entering the area or touching the level edge.
+func (level *Level) tunnelOut(start components.Position, area utils.Rect) {
+	inArea := func(x, y int) bool {
+		return x >= area.X1 && x <= area.X2 && y >= area.Y1 && y <= area.Y2
+	}
+
+	startIdx := level.GetIndexFromXY(start.X, start.Y)
+	parent := map[int]int{startIdx: -1}
+	queue := []int{startIdx}
+
+	for len(queue) > 0 {
+		idx := queue[0]
+		queue = queue[1:]
+
+		if idx != startIdx && level.Tiles[idx].TileType != WALL {
+			// Found open ground, so carve the walls on the way back
+			for step := parent[idx]; step != -1; step = parent[step] {
+				if tile := level.Tiles[step]; tile.TileType == WALL {
+					tile.Blocked = false
+					tile.TileType = FLOOR
+				}
+			}
+			return
+		}
+
+		cx, cy := idx%level.Width, idx/level.Width
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			nx, ny := cx+d[0], cy+d[1]
+			if nx <= 0 || ny <= 0 || nx >= level.Width-1 || ny >= level.Height-1 || inArea(nx, ny) {
+				continue
+			}
+			n := level.GetIndexFromXY(nx, ny)
+			if _, seen := parent[n]; !seen {
+				parent[n] = idx
+				queue = append(queue, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package level
+
+import (
+	"github.com/caustin/rrogue/utils"
+	"testing"
+)
+
+const testVault = `
+; A test vault
+#####
+#M.I#
+#.=.#
+##+##
+`
+
+func TestParseVault(t *testing.T) {
+	v, err := ParseVault("test", testVault)
+	if err != nil {
+		t.Fatalf("ParseVault returned error: %v", err)
+	}
+	if v.Width != 5 || v.Height != 4 {
+		t.Errorf("size = %dx%d, expected 5x4", v.Width, v.Height)
+	}
+	if entrances := v.entrances(); len(entrances) != 1 || entrances[0].X != 2 || entrances[0].Y != 3 {
+		t.Errorf("entrances = %v, expected the door at 2,3", entrances)
+	}
+}
+
+func TestParseVaultErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: "; nothing here\n"},
+		{name: "ragged rows", data: "###\n#.\n#+#\n"},
+		{name: "unknown tile", data: "#+#\n#X#\n###\n"},
+		{name: "no entrance", data: "###\n#.#\n###\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseVault(tt.name, tt.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadVaults(t *testing.T) {
+	vaults, err := LoadVaults("../assets/vaults")
+	if err != nil {
+		t.Fatalf("LoadVaults returned error: %v", err)
+	}
+	if len(vaults) == 0 {
+		t.Fatal("expected the vaults shipped in assets/vaults")
+	}
+	for i := 1; i < len(vaults); i++ {
+		if vaults[i-1].Name > vaults[i].Name {
+			t.Errorf("vaults are not in name order: %s before %s", vaults[i-1].Name, vaults[i].Name)
+		}
+	}
+}
+
+func TestStampVault(t *testing.T) {
+	v, _ := ParseVault("test", testVault)
+	level := Level{Width: 20, Height: 20}
+	level.Tiles = level.createTiles()
+	level.Rooms = []utils.Rect{utils.NewRect(1, 12, 6, 6)}
+	level.createRoom(level.Rooms[0])
+
+	level.stampVault(v, 10, 3)
+
+	if tile := level.Tiles[level.GetIndexFromXY(12, 5)]; tile.TileType != DOOR || tile.DoorState != DoorLocked {
+		t.Error("expected the locked door drawn in the vault")
+	}
+	if tile := level.Tiles[level.GetIndexFromXY(12, 6)]; tile.TileType != DOOR || tile.DoorState != DoorClosed {
+		t.Error("expected the vault entrance door")
+	}
+	if len(level.SpawnPoints) != 2 {
+		t.Fatalf("expected 2 spawn points, got %d", len(level.SpawnPoints))
+	}
+	monster, item := level.SpawnPoints[0], level.SpawnPoints[1]
+	if monster.Kind != SpawnMonster || monster.Position.X != 11 || monster.Position.Y != 4 {
+		t.Errorf("monster spawn = %+v, expected a monster at 11,4", monster)
+	}
+	if item.Kind != SpawnItem || item.Position.X != 13 || item.Position.Y != 4 {
+		t.Errorf("item spawn = %+v, expected an item at 13,4", item)
+	}
+	if report := level.CheckConnectivity(); !report.Connected() {
+		t.Errorf("expected the vault to be tunnelled to the room, %d regions unreachable", len(report.Unreachable))
+	}
+}
+
+func TestGeneratedLevelsWithVaults(t *testing.T) {
+	vaults, err := LoadVaults("../assets/vaults")
+	if err != nil {
+		t.Fatalf("LoadVaults returned error: %v", err)
+	}
+
+	stamped := 0
+	for seed := int64(1); seed <= 300; seed++ {
+		rng := utils.NewSeededRNG(seed)
+		level := Level{Width: 80, Height: 50, Depth: 1}
+		level.GenerateLevelTiles(RoomsGenerator{}, rng, vaults...)
+
+		if len(level.SpawnPoints) > 0 {
+			stamped++
+		}
+		if report := level.CheckConnectivity(); !report.Connected() {
+			t.Fatalf("seed %d: %d regions unreachable", seed, len(report.Unreachable))
+		}
+		for _, spawn := range level.SpawnPoints {
+			if tile := level.Tiles[level.GetIndexFromXY(spawn.Position.X, spawn.Position.Y)]; tile.TileType != FLOOR {
+				t.Fatalf("seed %d: spawn point %+v is not on the floor", seed, spawn)
+			}
+		}
+	}
+
+	if stamped == 0 {
+		t.Error("expected some levels to have a vault")
+	}
+}
@@ -1,21 +1,72 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"github.com/caustin/rrogue/game"
+	"github.com/caustin/rrogue/utils"
 	_ "image/png"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	seed := flag.Int64("seed", 0, "seed for the run; 0 picks a random seed")
+	savePath := flag.String("save", "rrogue.sav", "file the game is saved to on quit and resumed from on start")
+	newGame := flag.Bool("new", false, "start a new game even if there is a save to resume")
+	recordPath := flag.String("record", "rrogue.replay", "file the run's replay is written to on quit; empty turns recording off")
+	replayPath := flag.String("replay", "", "replay file to play back instead of playing")
+	replayDelay := flag.Duration("replay-delay", game.DefaultReplayDelay, "time between commands when playing a replay back")
+	flag.Parse()
 
-	g := game.NewGame()
+	if *replayPath != "" {
+		replay, err := game.LoadReplay(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Playing replay %s of seed %d", *replayPath, replay.Seed)
+		g := game.NewReplayGame(replay)
+		g.Playback.Delay = *replayDelay
+		run(g)
+		return
+	}
+
+	var g *game.Game
+	// Asking for a seed means asking for a new run
+	if !*newGame && *seed == 0 {
+		var err error
+		g, err = game.LoadGame(*savePath)
+		switch {
+		case err == nil:
+			log.Printf("Resuming game from %s", *savePath)
+		case errors.Is(err, os.ErrNotExist):
+		default:
+			log.Printf("Could not resume saved game, starting a new one: %v", err)
+		}
+	}
+
+	if g == nil {
+		if *seed == 0 {
+			*seed = utils.NewSeed()
+		}
+		log.Printf("Starting game with seed %d", *seed)
+		g = game.NewGameWithSeed(*seed)
+	}
+	g.SavePath = *savePath
+	g.ReplayPath = *recordPath
+	run(g)
+}
+
+// run opens the window and plays g until the window is closed.
+func run(g *game.Game) {
 	ebiten.SetWindowResizable(true)
+	ebiten.SetWindowClosingHandled(true)
 
 	ebiten.SetWindowTitle("Tower")
 
-	if err := ebiten.RunGame(g); err != nil {
+	if err := ebiten.RunGame(g); err != nil && !errors.Is(err, game.ErrQuit) {
 		log.Fatal(err)
 	}
 }
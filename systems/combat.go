@@ -2,24 +2,30 @@ package systems
 
 import (
 	"fmt"
-	"github.com/bytearena/ecs"
 	"github.com/caustin/rrogue/components"
 	"github.com/caustin/rrogue/events"
 	"github.com/caustin/rrogue/utils"
 	"github.com/caustin/rrogue/world"
+	"log"
 )
 
+// toHitDice is rolled and added to the weapon's to-hit bonus, which must
+// beat the defender's armor class for an attack to land.
+var toHitDice = utils.MustParseDice("1d10")
+
 // CombatSystem handles all combat-related operations
 type CombatSystem struct {
 	world    world.WorldService
 	eventBus *events.EventBus
+	rng      utils.RNG
 }
 
 // NewCombatSystem creates a new combat system with dependencies
-func NewCombatSystem(world world.WorldService, eventBus *events.EventBus) *CombatSystem {
+func NewCombatSystem(world world.WorldService, eventBus *events.EventBus, rng utils.RNG) *CombatSystem {
 	return &CombatSystem{
 		world:    world,
 		eventBus: eventBus,
+		rng:      rng,
 	}
 }
 
@@ -33,22 +39,41 @@ func (cs *CombatSystem) RegisterHandlers() {
 func (cs *CombatSystem) HandleAttack(event events.Event) {
 	attackEvent := event.(*events.AttackEvent)
 
+	// Either side may have been disposed since the attack was published
+	attacker, ok := cs.world.GetEntity(attackEvent.Attacker)
+	if !ok {
+		return
+	}
+	defender, ok := cs.world.GetEntity(attackEvent.Defender)
+	if !ok {
+		return
+	}
+
 	// Get component data
-	defenderArmor := cs.world.GetArmor(attackEvent.Defender)
-	attackerWeapon := cs.world.GetMeleeWeapon(attackEvent.Attacker)
-	attackerName := cs.world.GetName(attackEvent.Attacker).Label
-	defenderName := cs.world.GetName(attackEvent.Defender).Label
+	defenderArmor := cs.world.GetArmor(defender)
+	attackerWeapon := cs.world.GetMeleeWeapon(attacker)
+	attackerName := cs.world.GetName(attacker).Label
+	defenderName := cs.world.GetName(defender).Label
 
 	// Check if attacker is alive
-	if cs.world.GetHealth(attackEvent.Attacker).CurrentHealth <= 0 {
+	if cs.world.GetHealth(attacker).CurrentHealth <= 0 {
 		return
 	}
 
 	// Determine hit/miss based on the attack event data
 	if attackEvent.Hit {
 		// Calculate damage
-		damageRoll := utils.GetRandomBetween(attackerWeapon.MinimumDamage, attackerWeapon.MaximumDamage)
-		damageDone := damageRoll - defenderArmor.Defense
+		damageRoll, err := utils.RollDice(cs.rng, attackerWeapon.Damage)
+		if err != nil {
+			log.Printf("%s has invalid weapon damage: %v", attackerName, err)
+			return
+		}
+		defenseRoll, err := utils.RollDice(cs.rng, defenderArmor.Defense)
+		if err != nil {
+			log.Printf("%s has invalid armor defense: %v", defenderName, err)
+			return
+		}
+		damageDone := damageRoll.Total - defenseRoll.Total
 
 		// Ensure no negative damage (healing)
 		if damageDone < 0 {
@@ -78,15 +103,24 @@ func (cs *CombatSystem) HandleAttack(event events.Event) {
 func (cs *CombatSystem) HandleDamage(event events.Event) {
 	damageEvent := event.(*events.DamageEvent)
 
+	target, ok := cs.world.GetEntity(damageEvent.Target)
+	if !ok {
+		return
+	}
+
 	// Apply damage
-	defenderHealth := cs.world.GetHealth(damageEvent.Target)
+	defenderHealth := cs.world.GetHealth(target)
 	defenderHealth.CurrentHealth -= damageEvent.DamageAmount
 
 	// Check for death
 	if defenderHealth.CurrentHealth <= 0 {
-		defenderPos := cs.world.GetPosition(damageEvent.Target)
-		defenderName := cs.world.GetName(damageEvent.Target).Label
+		defenderPos := cs.world.GetPosition(target)
+		defenderName := cs.world.GetName(target).Label
 		isPlayer := defenderName == "Player"
+		template := ""
+		if !isPlayer {
+			template = cs.world.GetMonster(target).Template
+		}
 
 		// Publish death message event
 		deathMessage := fmt.Sprintf("%s has died!\n", defenderName)
@@ -101,54 +135,36 @@ func (cs *CombatSystem) HandleDamage(event events.Event) {
 			// TODO: Set game over state via event
 		} else {
 			// Clean up the monster entity
-			cs.world.DisposeEntity(damageEvent.Target)
+			cs.world.DisposeEntity(target)
 			// Note: Map tile unblocking will need to be handled externally
 			// until we implement a proper MapSystem
 		}
 
 		// Publish death event for future event handlers
-		deathEvent := events.NewDeathEvent(damageEvent.Target, defenderPos, isPlayer)
+		deathEvent := events.NewDeathEvent(damageEvent.Target, defenderPos, isPlayer, template)
 		cs.eventBus.Publish(deathEvent)
 	}
 }
 
 // ProcessAttack is a helper function to initiate an attack between two positions
 func (cs *CombatSystem) ProcessAttack(attackerPos, defenderPos *components.Position) {
-	var attacker, defender *ecs.QueryResult = nil, nil
-
 	// Find attacker and defender entities at the given positions
-	for _, player := range cs.world.QueryPlayers() {
-		pos := cs.world.GetPosition(player)
-		if pos.IsEqual(attackerPos) {
-			attacker = player
-		}
-		if pos.IsEqual(defenderPos) {
-			defender = player
-		}
-	}
-
-	for _, monster := range cs.world.QueryMonsters() {
-		pos := cs.world.GetPosition(monster)
-		if pos.IsEqual(attackerPos) {
-			attacker = monster
-		}
-		if pos.IsEqual(defenderPos) {
-			defender = monster
-		}
+	attacker, ok := cs.world.EntityAt(attackerPos.X, attackerPos.Y)
+	if !ok {
+		return
 	}
-
-	// Ensure we have both attacker and defender
-	if attacker == nil || defender == nil {
+	defender, ok := cs.world.EntityAt(defenderPos.X, defenderPos.Y)
+	if !ok {
 		return
 	}
 
 	// Roll to hit
-	toHitRoll := utils.GetDiceRoll(10)
+	toHitRoll := toHitDice.Roll(cs.rng).Total
 	attackerWeapon := cs.world.GetMeleeWeapon(attacker)
 	defenderArmor := cs.world.GetArmor(defender)
 	hit := toHitRoll+attackerWeapon.ToHitBonus > defenderArmor.ArmorClass
 
 	// Publish attack event
-	attackEvent := events.NewAttackEvent(attacker, defender, attackerPos, defenderPos, toHitRoll, hit)
+	attackEvent := events.NewAttackEvent(cs.world.ID(attacker), cs.world.ID(defender), attackerPos, defenderPos, toHitRoll, hit)
 	cs.eventBus.Publish(attackEvent)
 }
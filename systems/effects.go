@@ -0,0 +1,184 @@
+package systems
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/events"
+	"github.com/caustin/rrogue/level"
+	"github.com/caustin/rrogue/templates"
+	"github.com/caustin/rrogue/utils"
+	"github.com/caustin/rrogue/world"
+)
+
+// EffectSystem carries out what potions and scrolls do once they are used.
+// Each effect is published as its own event so other systems can react to
+// it: healing as a HealEvent, teleporting as a TeleportEvent, mapping as a
+// MapRevealedEvent and fire as a DamageEvent for everything it burns. Using
+// an item identifies it, which is published as an ItemIdentifiedEvent.
+type EffectSystem struct {
+	world    world.WorldService
+	eventBus *events.EventBus
+	rng      utils.RNG
+
+	// The level the player is on, which teleporting and mapping act on
+	currentLevel func() level.Level
+}
+
+// NewEffectSystem creates a new effect system with dependencies
+func NewEffectSystem(world world.WorldService, eventBus *events.EventBus, rng utils.RNG) *EffectSystem {
+	return &EffectSystem{
+		world:    world,
+		eventBus: eventBus,
+		rng:      rng,
+	}
+}
+
+// SetLevel gives the system a way to find the level the player is on. Until
+// it is set, teleporting and mapping do nothing.
+func (es *EffectSystem) SetLevel(currentLevel func() level.Level) {
+	es.currentLevel = currentLevel
+}
+
+// RegisterHandlers subscribes the effect system to relevant events
+func (es *EffectSystem) RegisterHandlers() {
+	es.eventBus.Subscribe(events.ItemUsedEventType, es.HandleItemUsed)
+}
+
+// HandleItemUsed applies the effect of a used item to its user
+func (es *EffectSystem) HandleItemUsed(event events.Event) {
+	usedEvent := event.(*events.ItemUsedEvent)
+
+	user, ok := es.world.GetEntity(usedEvent.User)
+	if !ok {
+		return
+	}
+
+	switch usedEvent.Item.Effect {
+	case templates.EffectHeal:
+		es.heal(user, usedEvent)
+	case templates.EffectTeleport:
+		es.teleport(user)
+	case templates.EffectMapping:
+		es.revealMap()
+	case templates.EffectFire:
+		es.burn(usedEvent)
+	case templates.EffectIdentify:
+		es.identifyPack(user)
+	}
+
+	if name, newly := es.world.Identify(usedEvent.Item.Template); newly {
+		es.message(fmt.Sprintf("That was a %s.\n", name))
+		es.eventBus.Publish(events.NewItemIdentifiedEvent(usedEvent.Item.Template, name))
+	}
+}
+
+// heal restores the item's power in health, up to the user's maximum.
+func (es *EffectSystem) heal(user *ecs.QueryResult, usedEvent *events.ItemUsedEvent) {
+	roll, err := utils.RollDice(es.rng, usedEvent.Item.Power)
+	if err != nil {
+		log.Printf("%s has invalid power: %v", usedEvent.ItemName, err)
+		return
+	}
+
+	health := es.world.GetHealth(user)
+	amount := roll.Total
+	if amount > health.MaxHealth-health.CurrentHealth {
+		amount = health.MaxHealth - health.CurrentHealth
+	}
+	health.CurrentHealth += amount
+
+	es.message(fmt.Sprintf("You feel better, and regain %d health.\n", amount))
+	es.eventBus.Publish(events.NewHealEvent(usedEvent.User, amount, usedEvent.ItemName))
+}
+
+// teleport moves the user to a free floor tile in a random room of the
+// level.
+func (es *EffectSystem) teleport(user *ecs.QueryResult) {
+	if es.currentLevel == nil {
+		return
+	}
+	l := es.currentLevel()
+
+	room := l.Rooms[es.rng.GetRandomInt(len(l.Rooms))]
+	free := l.FreeTiles(room)
+	if len(free) == 0 {
+		es.message("You feel a tugging sensation, but nothing happens.\n")
+		return
+	}
+	to := free[es.rng.GetRandomInt(len(free))]
+
+	pos := es.world.GetPosition(user)
+	from := *pos
+	l.Tiles[l.GetIndexFromXY(from.X, from.Y)].Blocked = false
+	es.world.MoveEntity(user, to.X, to.Y)
+	l.Tiles[l.GetIndexFromXY(to.X, to.Y)].Blocked = true
+	l.PlayerVisible.Compute(l, to.X, to.Y, 8)
+
+	es.message("You are whisked away!\n")
+	es.eventBus.Publish(events.NewTeleportEvent(es.world.ID(user), &from, &to))
+}
+
+// revealMap reveals every tile of the level.
+func (es *EffectSystem) revealMap() {
+	if es.currentLevel == nil {
+		return
+	}
+	l := es.currentLevel()
+	for _, tile := range l.Tiles {
+		tile.IsRevealed = true
+	}
+
+	es.message("A map of the level forms in your mind.\n")
+	es.eventBus.Publish(events.NewMapRevealedEvent(l.Depth))
+}
+
+// burn rolls the item's power in damage against every creature within its
+// radius of where it was used, other than the user.
+func (es *EffectSystem) burn(usedEvent *events.ItemUsedEvent) {
+	es.message("Flames burst out around you!\n")
+
+	center := usedEvent.Position
+	for x := center.X - usedEvent.Item.Radius; x <= center.X+usedEvent.Item.Radius; x++ {
+		for y := center.Y - usedEvent.Item.Radius; y <= center.Y+usedEvent.Item.Radius; y++ {
+			target, ok := es.world.EntityAt(x, y)
+			if !ok || es.world.ID(target) == usedEvent.User {
+				continue
+			}
+			roll, err := utils.RollDice(es.rng, usedEvent.Item.Power)
+			if err != nil {
+				log.Printf("%s has invalid power: %v", usedEvent.ItemName, err)
+				return
+			}
+
+			es.message(fmt.Sprintf("%s is burned for %d health.\n", es.world.GetName(target).Label, roll.Total))
+			es.eventBus.Publish(events.NewDamageEvent(es.world.ID(target), roll.Total, usedEvent.ItemName, false))
+		}
+	}
+}
+
+// identifyPack identifies everything the user is carrying.
+func (es *EffectSystem) identifyPack(user *ecs.QueryResult) {
+	identified := 0
+	for _, id := range es.world.GetInventory(user).Items {
+		item, ok := es.world.GetEntity(id)
+		if !ok {
+			continue
+		}
+		template := es.world.GetItem(item).Template
+		appearance := es.world.GetName(item).Label
+		if name, newly := es.world.Identify(template); newly {
+			es.message(fmt.Sprintf("The %s is a %s.\n", appearance, name))
+			es.eventBus.Publish(events.NewItemIdentifiedEvent(template, name))
+			identified++
+		}
+	}
+	if identified == 0 {
+		es.message("You already know what everything you carry is.\n")
+	}
+}
+
+func (es *EffectSystem) message(text string) {
+	es.eventBus.Publish(events.NewMessageEvent(text, "info"))
+}
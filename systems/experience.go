@@ -0,0 +1,73 @@
+package systems
+
+import (
+	"fmt"
+
+	"github.com/caustin/rrogue/events"
+	"github.com/caustin/rrogue/world"
+)
+
+// What the player gains for each experience level
+const (
+	healthPerLevel = 8
+	toHitPerLevel  = 1
+)
+
+// XPForLevel returns the experience needed to reach level. Each level takes
+// 20 more than the one before it: 20 for level 2, 60 for level 3, 120 for
+// level 4 and so on.
+func XPForLevel(level int) int {
+	return 10 * level * (level - 1)
+}
+
+// ExperienceSystem awards experience for kills and levels the player up.
+// Only the player earns experience, so every monster's death counts, however
+// it died. Each level raises the player's maximum health and bonus to hit.
+type ExperienceSystem struct {
+	world    world.WorldService
+	eventBus *events.EventBus
+}
+
+// NewExperienceSystem creates a new experience system with dependencies
+func NewExperienceSystem(world world.WorldService, eventBus *events.EventBus) *ExperienceSystem {
+	return &ExperienceSystem{
+		world:    world,
+		eventBus: eventBus,
+	}
+}
+
+// RegisterHandlers subscribes the experience system to relevant events
+func (es *ExperienceSystem) RegisterHandlers() {
+	es.eventBus.Subscribe(events.DeathEventType, es.HandleDeath)
+}
+
+// HandleDeath gives the player the dead monster's experience, and as many
+// levels as that earns them
+func (es *ExperienceSystem) HandleDeath(event events.Event) {
+	deathEvent := event.(*events.DeathEvent)
+	if deathEvent.IsPlayer {
+		return
+	}
+	t, ok := es.world.FindMonster(deathEvent.Template)
+	if !ok || t.XP == 0 {
+		return
+	}
+
+	for _, player := range es.world.QueryPlayers() {
+		experience := es.world.GetExperience(player)
+		experience.XP += t.XP
+		es.eventBus.Publish(events.NewExperienceGainedEvent(es.world.ID(player), t.XP, t.Name))
+
+		for experience.XP >= XPForLevel(experience.Level+1) {
+			experience.Level++
+			experience.ToHitBonus += toHitPerLevel
+			health := es.world.GetHealth(player)
+			health.MaxHealth += healthPerLevel
+			health.CurrentHealth += healthPerLevel
+			es.world.ApplyEquipment(player)
+
+			es.eventBus.Publish(events.NewMessageEvent(fmt.Sprintf("Welcome to level %d! You feel more experienced.\n", experience.Level), "info"))
+			es.eventBus.Publish(events.NewLevelUpEvent(es.world.ID(player), experience.Level, healthPerLevel, toHitPerLevel))
+		}
+	}
+}
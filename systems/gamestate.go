@@ -163,6 +163,17 @@ func (gs *GameStateSystem) IncrementTurn() {
 	gs.eventBus.Publish(counterEvent)
 }
 
+// SetTurnCounter sets the turn counter, as when a saved game is resumed,
+// and publishes the change
+func (gs *GameStateSystem) SetTurnCounter(count int) {
+	gs.mutex.Lock()
+	gs.turnCounter = count
+	gs.mutex.Unlock()
+
+	counterEvent := events.NewTurnCounterEvent(count, 0)
+	gs.eventBus.Publish(counterEvent)
+}
+
 // GetCurrentState returns the current turn state (thread-safe)
 func (gs *GameStateSystem) GetCurrentState() TurnState {
 	gs.mutex.RLock()
@@ -0,0 +1,242 @@
+package systems
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/events"
+	"github.com/caustin/rrogue/world"
+)
+
+// InventorySystem handles picking up, dropping, equipping and using items.
+// Each action reports whether it took the entity's turn, and tells the
+// player what happened through message events.
+type InventorySystem struct {
+	world    world.WorldService
+	eventBus *events.EventBus
+}
+
+// NewInventorySystem creates a new inventory system with dependencies
+func NewInventorySystem(world world.WorldService, eventBus *events.EventBus) *InventorySystem {
+	return &InventorySystem{
+		world:    world,
+		eventBus: eventBus,
+	}
+}
+
+// PickUp picks up every item lying where entity stands, until its
+// inventory is full.
+func (is *InventorySystem) PickUp(entity *ecs.QueryResult) bool {
+	pos := is.world.GetPosition(entity)
+	items := is.world.ItemsAt(pos.X, pos.Y)
+	if len(items) == 0 {
+		is.message("There is nothing here to pick up.\n")
+		return false
+	}
+
+	inventory := is.world.GetInventory(entity)
+	picked := 0
+	for _, item := range items {
+		name := is.world.GetName(item).Label
+		if inventory.IsFull() {
+			is.message(fmt.Sprintf("You have no room for the %s.\n", name))
+			break
+		}
+		is.world.TakeItem(item)
+		inventory.Items = append(inventory.Items, is.world.ID(item))
+		is.message(fmt.Sprintf("You pick up the %s.\n", name))
+		picked++
+	}
+	return picked > 0
+}
+
+// CollectGold adds any gold lying where entity stands to what it carries.
+// Gold is picked up just by walking over it, and takes no turn.
+func (is *InventorySystem) CollectGold(entity *ecs.QueryResult) {
+	pos := is.world.GetPosition(entity)
+	for _, pile := range is.world.GoldAt(pos.X, pos.Y) {
+		amount := is.world.GetGold(pile).Amount
+		is.world.GetInventory(entity).Gold += amount
+		is.world.DisposeEntity(pile)
+		is.message(fmt.Sprintf("You pick up %d gold pieces.\n", amount))
+	}
+}
+
+// Drop puts the inventory item at index down where entity stands, taking it
+// off first if it is equipped.
+func (is *InventorySystem) Drop(entity *ecs.QueryResult, index int) bool {
+	item, ok := is.inventoryItem(entity, index)
+	if !ok {
+		return false
+	}
+	id := is.world.ID(item)
+	if is.world.GetEquipment(entity).IsEquipped(id) {
+		is.unequip(entity, id)
+	}
+
+	inventory := is.world.GetInventory(entity)
+	inventory.Items = append(inventory.Items[:index], inventory.Items[index+1:]...)
+	pos := is.world.GetPosition(entity)
+	is.world.PlaceItem(item, pos.X, pos.Y)
+	is.message(fmt.Sprintf("You drop the %s.\n", is.world.GetName(item).Label))
+	return true
+}
+
+// Equip wields or puts on the inventory item at index. Whatever was in its
+// slot is taken off; a ring goes on a free hand, or replaces the ring that
+// has been worn longest.
+func (is *InventorySystem) Equip(entity *ecs.QueryResult, index int) bool {
+	item, ok := is.inventoryItem(entity, index)
+	if !ok {
+		return false
+	}
+	id := is.world.ID(item)
+	name := is.world.GetName(item).Label
+	slot := is.world.GetItem(item).Slot
+	equipment := is.world.GetEquipment(entity)
+
+	switch {
+	case slot == "":
+		is.message(fmt.Sprintf("You can't equip the %s.\n", name))
+		return false
+	case equipment.IsEquipped(id):
+		is.message(fmt.Sprintf("You already have the %s equipped.\n", name))
+		return false
+	case slot == components.SlotRing:
+		if len(equipment.Rings) >= components.RingSlots {
+			is.unequip(entity, equipment.Rings[0])
+		}
+		equipment.Rings = append(equipment.Rings, id)
+	default:
+		if current, ok := equipment.Slots[slot]; ok {
+			is.unequip(entity, current)
+		}
+		if equipment.Slots == nil {
+			equipment.Slots = make(map[components.Slot]ecs.EntityID)
+		}
+		equipment.Slots[slot] = id
+	}
+
+	is.world.ApplyEquipment(entity)
+	if slot == components.SlotWeapon {
+		is.message(fmt.Sprintf("You wield the %s.\n", name))
+	} else {
+		is.message(fmt.Sprintf("You put on the %s.\n", name))
+	}
+	return true
+}
+
+// Remove takes off or puts away the equipped inventory item at index.
+func (is *InventorySystem) Remove(entity *ecs.QueryResult, index int) bool {
+	item, ok := is.inventoryItem(entity, index)
+	if !ok {
+		return false
+	}
+	id := is.world.ID(item)
+	if !is.world.GetEquipment(entity).IsEquipped(id) {
+		is.message(fmt.Sprintf("You don't have the %s equipped.\n", is.world.GetName(item).Label))
+		return false
+	}
+	is.unequip(entity, id)
+	return true
+}
+
+// useVerbs are what using each kind of consumable is called.
+var useVerbs = map[string]string{"potion": "quaff", "scroll": "read"}
+
+// Use quaffs or reads the inventory item at index, using it up. What it
+// does is left to whoever handles the ItemUsedEvent published for it.
+func (is *InventorySystem) Use(entity *ecs.QueryResult, index int) bool {
+	item, ok := is.inventoryItem(entity, index)
+	if !ok {
+		return false
+	}
+	data := *is.world.GetItem(item)
+	name := is.world.GetName(item).Label
+	if data.Effect == "" {
+		is.message(fmt.Sprintf("You can't use the %s.\n", name))
+		return false
+	}
+
+	inventory := is.world.GetInventory(entity)
+	inventory.Items = append(inventory.Items[:index], inventory.Items[index+1:]...)
+	is.world.DisposeEntity(item)
+	is.message(fmt.Sprintf("You %s the %s.\n", useVerbs[data.Kind], name))
+
+	pos := *is.world.GetPosition(entity)
+	is.eventBus.Publish(events.NewItemUsedEvent(is.world.ID(entity), data, name, &pos))
+	return true
+}
+
+// Describe lists the entity's inventory, two items to a line, each with
+// the letter that picks it and whether it is equipped.
+func (is *InventorySystem) Describe(entity *ecs.QueryResult) []string {
+	inventory := is.world.GetInventory(entity)
+	if len(inventory.Items) == 0 {
+		return []string{"You are carrying nothing.\n"}
+	}
+	lines := []string{fmt.Sprintf("You are carrying %d of %d items:\n", len(inventory.Items), inventory.Capacity)}
+
+	equipment := is.world.GetEquipment(entity)
+	var entries []string
+	for i, id := range inventory.Items {
+		item, ok := is.world.GetEntity(id)
+		if !ok {
+			continue
+		}
+		entry := fmt.Sprintf("%c) %s", 'a'+i, is.world.GetName(item).Label)
+		if equipment.IsEquipped(id) {
+			entry += " (equipped)"
+		}
+		entries = append(entries, entry)
+	}
+	for i := 0; i < len(entries); i += 2 {
+		end := i + 2
+		if end > len(entries) {
+			end = len(entries)
+		}
+		lines = append(lines, strings.Join(entries[i:end], "   ")+"\n")
+	}
+	return lines
+}
+
+// inventoryItem returns the item at index in the entity's inventory.
+func (is *InventorySystem) inventoryItem(entity *ecs.QueryResult, index int) (*ecs.QueryResult, bool) {
+	inventory := is.world.GetInventory(entity)
+	if index < 0 || index >= len(inventory.Items) {
+		is.message(fmt.Sprintf("You have no item %c.\n", 'a'+index))
+		return nil, false
+	}
+	return is.world.GetEntity(inventory.Items[index])
+}
+
+// unequip takes an equipped item off and updates the entity's stats.
+func (is *InventorySystem) unequip(entity *ecs.QueryResult, id ecs.EntityID) {
+	equipment := is.world.GetEquipment(entity)
+	for slot, equipped := range equipment.Slots {
+		if equipped == id {
+			delete(equipment.Slots, slot)
+		}
+	}
+	for i, equipped := range equipment.Rings {
+		if equipped == id {
+			equipment.Rings = append(equipment.Rings[:i], equipment.Rings[i+1:]...)
+			break
+		}
+	}
+	is.world.ApplyEquipment(entity)
+
+	if item, ok := is.world.GetEntity(id); ok {
+		if is.world.GetItem(item).Slot == components.SlotWeapon {
+			is.message(fmt.Sprintf("You put away the %s.\n", is.world.GetName(item).Label))
+		} else {
+			is.message(fmt.Sprintf("You take off the %s.\n", is.world.GetName(item).Label))
+		}
+	}
+}
+
+func (is *InventorySystem) message(text string) {
+	is.eventBus.Publish(events.NewMessageEvent(text, "info"))
+}
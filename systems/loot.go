@@ -0,0 +1,82 @@
+package systems
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/events"
+	"github.com/caustin/rrogue/utils"
+	"github.com/caustin/rrogue/world"
+)
+
+// LootSystem leaves behind what monsters drop when they die. It listens for
+// DeathEvent, lays the monster's corpse on the tile it died on and rolls its
+// loot table for items and gold to go with it, then publishes a
+// LootDroppedEvent if anything was left.
+type LootSystem struct {
+	world    world.WorldService
+	eventBus *events.EventBus
+	rng      utils.RNG
+}
+
+// NewLootSystem creates a new loot system with dependencies
+func NewLootSystem(world world.WorldService, eventBus *events.EventBus, rng utils.RNG) *LootSystem {
+	return &LootSystem{
+		world:    world,
+		eventBus: eventBus,
+		rng:      rng,
+	}
+}
+
+// RegisterHandlers subscribes the loot system to relevant events
+func (ls *LootSystem) RegisterHandlers() {
+	ls.eventBus.Subscribe(events.DeathEventType, ls.HandleDeath)
+}
+
+// HandleDeath drops a dead monster's corpse and loot where it died
+func (ls *LootSystem) HandleDeath(event events.Event) {
+	deathEvent := event.(*events.DeathEvent)
+	if deathEvent.IsPlayer {
+		return
+	}
+	t, ok := ls.world.FindMonster(deathEvent.Template)
+	if !ok {
+		return
+	}
+	pos := deathEvent.Position
+
+	_, corpse := ls.world.SpawnCorpse(t, pos.X, pos.Y)
+
+	var items []ecs.EntityID
+	for _, drop := range t.Loot.Drops {
+		if ls.rng.GetRandomInt(100) >= drop.Chance {
+			continue
+		}
+		if id, ok := ls.world.SpawnItem(drop.Item, pos.X, pos.Y); ok {
+			items = append(items, id)
+			item, _ := ls.world.GetEntity(id)
+			ls.message(fmt.Sprintf("%s drops the %s.\n", t.Name, ls.world.GetName(item).Label))
+		}
+	}
+
+	gold := 0
+	if t.Loot.Gold != "" {
+		roll, err := utils.RollDice(ls.rng, t.Loot.Gold)
+		if err != nil {
+			log.Printf("%s has invalid loot gold: %v", t.Name, err)
+		} else if roll.Total > 0 {
+			gold = roll.Total
+			ls.world.SpawnGold(gold, pos.X, pos.Y)
+			ls.message(fmt.Sprintf("%s drops %d gold pieces.\n", t.Name, gold))
+		}
+	}
+
+	if corpse || len(items) > 0 || gold > 0 {
+		ls.eventBus.Publish(events.NewLootDroppedEvent(t.Name, pos, corpse, items, gold))
+	}
+}
+
+func (ls *LootSystem) message(text string) {
+	ls.eventBus.Publish(events.NewMessageEvent(text, "info"))
+}
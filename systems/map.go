@@ -44,8 +44,10 @@ func (ms *MapSystem) HandleEntityDeath(event events.Event) {
 		// Monster died - unblock the tile
 		ms.mapManager.UnblockTile(deathEvent.Position.X, deathEvent.Position.Y)
 
-		// Dispose the entity from the world
-		ms.world.DisposeEntity(deathEvent.Entity)
+		// Dispose the entity from the world, unless that has already happened
+		if entity, ok := ms.world.GetEntity(deathEvent.Entity); ok {
+			ms.world.DisposeEntity(entity)
+		}
 
 		// Publish tile unblocked event
 		tileEvent := events.NewTileUnblockedEvent(deathEvent.Position, "monster_death")
@@ -2,12 +2,17 @@ package systems
 
 import (
 	"github.com/caustin/rrogue/events"
+	"github.com/caustin/rrogue/utils"
 	"github.com/caustin/rrogue/world"
 )
 
 // SystemRegistry manages all event-driven systems and their lifecycle
 type SystemRegistry struct {
 	Combat     *CombatSystem
+	Inventory  *InventorySystem
+	Effects    *EffectSystem
+	Loot       *LootSystem
+	Experience *ExperienceSystem
 	GameState  *GameStateSystem
 	Map        *MapSystem
 	GameBridge *GameBridge
@@ -20,14 +25,18 @@ type SystemRegistry struct {
 }
 
 // NewSystemRegistry creates and initializes all systems with their dependencies
-func NewSystemRegistry(world world.WorldService, eventBus *events.EventBus) *SystemRegistry {
+func NewSystemRegistry(world world.WorldService, eventBus *events.EventBus, rng utils.RNG) *SystemRegistry {
 	registry := &SystemRegistry{
 		world:    world,
 		eventBus: eventBus,
 	}
 
 	// Create systems with dependencies
-	registry.Combat = NewCombatSystem(world, eventBus)
+	registry.Combat = NewCombatSystem(world, eventBus, rng)
+	registry.Inventory = NewInventorySystem(world, eventBus)
+	registry.Effects = NewEffectSystem(world, eventBus, rng)
+	registry.Loot = NewLootSystem(world, eventBus, rng)
+	registry.Experience = NewExperienceSystem(world, eventBus)
 	registry.GameBridge = NewGameBridge(eventBus)
 	registry.MapBridge = NewMapBridge(eventBus)
 	registry.UI = NewUISystem(world, eventBus)
@@ -44,6 +53,9 @@ func NewSystemRegistry(world world.WorldService, eventBus *events.EventBus) *Sys
 // RegisterAllHandlers subscribes all systems to their respective events
 func (r *SystemRegistry) RegisterAllHandlers() {
 	r.Combat.RegisterHandlers()
+	r.Effects.RegisterHandlers()
+	r.Loot.RegisterHandlers()
+	r.Experience.RegisterHandlers()
 	r.UI.RegisterHandlers()
 
 	if r.GameState != nil {
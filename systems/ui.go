@@ -118,6 +118,18 @@ func (ui *UISystem) ClearMessages(clearAll bool) {
 	ui.eventBus.Publish(clearEvent)
 }
 
+// RestoreMessages replaces the message log with one from a saved game
+func (ui *UISystem) RestoreMessages(messages []UIMessage) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	ui.messages = make([]UIMessage, len(messages))
+	copy(ui.messages, messages)
+	if len(ui.messages) > ui.maxMessages {
+		ui.messages = ui.messages[len(ui.messages)-ui.maxMessages:]
+	}
+}
+
 // GetMessageCount returns the current number of messages
 func (ui *UISystem) GetMessageCount() int {
 	ui.mutex.RLock()
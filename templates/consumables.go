@@ -0,0 +1,132 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/caustin/rrogue/utils"
+)
+
+// ConsumablesFile is the file Load reads consumable templates from.
+const ConsumablesFile = "consumables.json"
+
+// The effects a consumable can have when it is used.
+const (
+	EffectHeal     = "heal"     // Restore Power health to the user
+	EffectTeleport = "teleport" // Move the user to a random free tile
+	EffectMapping  = "mapping"  // Reveal the whole level
+	EffectFire     = "fire"     // Burn everything within Radius for Power damage
+	EffectIdentify = "identify" // Identify everything the user is carrying
+)
+
+// effectPower says whether each effect needs Power dice.
+var effectPower = map[string]bool{
+	EffectHeal:     true,
+	EffectTeleport: false,
+	EffectMapping:  false,
+	EffectFire:     true,
+	EffectIdentify: false,
+}
+
+// ConsumableTemplate describes an item that is used up when it is used.
+// Kind is potion, which is quaffed, or scroll, which is read. Power is dice
+// notation for how strong the effect is, and Radius how far a fire reaches.
+type ConsumableTemplate struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Sprite string `json:"sprite"`
+	Kind   string `json:"kind"`
+	Effect string `json:"effect"`
+	Power  string `json:"power,omitempty"`
+	Radius int    `json:"radius,omitempty"`
+	Spawn
+}
+
+// appearances are what each kind of consumable can look like before it is
+// identified. Every template of a kind needs one of its own.
+var appearances = map[string][]string{
+	"potion": {
+		"Murky Potion", "Bubbling Potion", "Smoky Potion", "Golden Potion",
+		"Violet Potion", "Cloudy Potion", "Fizzy Potion", "Crimson Potion",
+	},
+	"scroll": {
+		"Scroll labeled ZELGO MER", "Scroll labeled FOOBIE BLETCH", "Scroll labeled XIXAXA XOXAXA",
+		"Scroll labeled ELBIB YLOH", "Scroll labeled VERR YED HORRE", "Scroll labeled THARR",
+		"Scroll labeled NR 9", "Scroll labeled PRATYAVAYAH",
+	},
+}
+
+// consumableKinds are the kinds of consumable, in the order their
+// appearances are shuffled.
+var consumableKinds = []string{"potion", "scroll"}
+
+// FindConsumable returns the consumable template with the given ID.
+func (r *Registry) FindConsumable(id string) (ConsumableTemplate, bool) {
+	for _, c := range r.Consumables {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return ConsumableTemplate{}, false
+}
+
+// ShuffleAppearances gives each consumable template a different appearance
+// of its kind, by template ID. The shuffle is drawn from rng, so every run
+// has its own and the same seed always has the same.
+func (r *Registry) ShuffleAppearances(rng utils.RNG) map[string]string {
+	shuffled := make(map[string]string)
+	for _, kind := range consumableKinds {
+		looks := append([]string{}, appearances[kind]...)
+		for i := len(looks) - 1; i > 0; i-- {
+			j := rng.GetRandomInt(i + 1)
+			looks[i], looks[j] = looks[j], looks[i]
+		}
+
+		next := 0
+		for _, c := range r.Consumables {
+			if c.Kind == kind && next < len(looks) {
+				shuffled[c.ID] = looks[next]
+				next++
+			}
+		}
+	}
+	return shuffled
+}
+
+// validateConsumables checks the consumable templates. IDs are shared with
+// weapons and armor, through seen, since they all lie on the floor as items.
+func (r *Registry) validateConsumables(seen map[string]bool) []error {
+	var errs []error
+	perKind := make(map[string]int)
+	for _, c := range r.Consumables {
+		fail := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf("%s: consumable %q: %s", ConsumablesFile, c.ID, fmt.Sprintf(format, args...)))
+		}
+
+		for _, problem := range checkCommon(c.ID, c.Name, c.Spawn, seen) {
+			fail("%s", problem)
+		}
+		if c.Sprite == "" {
+			fail("sprite is missing")
+		}
+		if looks, ok := appearances[c.Kind]; !ok {
+			fail("kind %q must be potion or scroll", c.Kind)
+		} else {
+			perKind[c.Kind]++
+			if perKind[c.Kind] > len(looks) {
+				fail("there are only %d %s appearances to go around", len(looks), c.Kind)
+			}
+		}
+		needsPower, ok := effectPower[c.Effect]
+		if !ok {
+			fail("effect %q must be heal, teleport, mapping, fire or identify", c.Effect)
+		} else if needsPower {
+			if _, err := utils.ParseDice(c.Power); err != nil {
+				fail("power: %v", err)
+			}
+		}
+		if c.Effect == EffectFire && c.Radius < 1 {
+			fail("radius is %d, must be at least 1", c.Radius)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,60 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/caustin/rrogue/utils"
+)
+
+// LootTable is what a monster can leave behind when it dies. Gold is dice
+// notation for the gold pieces it drops, and each drop is rolled for on its
+// own.
+type LootTable struct {
+	Gold  string     `json:"gold,omitempty"`
+	Drops []LootDrop `json:"drops,omitempty"`
+}
+
+// LootDrop is an item a monster drops Chance times in 100. Item is the ID of
+// a weapon, armor or consumable template with a sprite.
+type LootDrop struct {
+	Item   string `json:"item"`
+	Chance int    `json:"chance"`
+}
+
+// IsEmpty reports whether the table can never drop anything.
+func (l LootTable) IsEmpty() bool {
+	return l.Gold == "" && len(l.Drops) == 0
+}
+
+// validateLoot checks a monster's loot table, returning a problem for each
+// mistake. Items must already have been loaded.
+func (r *Registry) validateLoot(loot LootTable) []string {
+	var problems []string
+	if loot.Gold != "" {
+		if _, err := utils.ParseDice(loot.Gold); err != nil {
+			problems = append(problems, fmt.Sprintf("loot gold: %v", err))
+		}
+	}
+	for _, drop := range loot.Drops {
+		if !r.isItem(drop.Item) {
+			problems = append(problems, fmt.Sprintf("loot item %q is not a weapon, armor or consumable with a sprite", drop.Item))
+		}
+		if drop.Chance < 1 || drop.Chance > 100 {
+			problems = append(problems, fmt.Sprintf("loot chance for %q is %d, must be 1 to 100", drop.Item, drop.Chance))
+		}
+	}
+	return problems
+}
+
+// isItem reports whether id is a template that can lie on the floor as an
+// item.
+func (r *Registry) isItem(id string) bool {
+	if w, ok := r.FindWeapon(id); ok {
+		return w.Sprite != ""
+	}
+	if a, ok := r.FindArmor(id); ok {
+		return a.Sprite != ""
+	}
+	_, ok := r.FindConsumable(id)
+	return ok
+}
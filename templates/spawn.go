@@ -0,0 +1,113 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/caustin/rrogue/utils"
+)
+
+// SpawnsFile is the file Load reads spawn tables from.
+const SpawnsFile = "spawns.json"
+
+// SpawnTable says which monsters live in the rooms of a range of depths.
+// Each room gets between MinPerRoom and MaxPerRoom monsters, made up of
+// packs drawn from the weighted entries, and each level gets between
+// MinItems and MaxItems items scattered through its rooms. MaxDepth 0 means
+// there is no deepest level.
+type SpawnTable struct {
+	MinDepth   int          `json:"min_depth"`
+	MaxDepth   int          `json:"max_depth,omitempty"`
+	MinPerRoom int          `json:"min_per_room"`
+	MaxPerRoom int          `json:"max_per_room"`
+	MinItems   int          `json:"min_items,omitempty"`
+	MaxItems   int          `json:"max_items,omitempty"`
+	Entries    []SpawnEntry `json:"entries"`
+}
+
+// SpawnEntry is one choice in a spawn table: a pack of between PackMin and
+// PackMax of a monster, picked in proportion to Weight.
+type SpawnEntry struct {
+	Monster string `json:"monster"`
+	Weight  int    `json:"weight"`
+	PackMin int    `json:"pack_min"`
+	PackMax int    `json:"pack_max"`
+}
+
+// AllowedAt reports whether the table is for depth.
+func (t SpawnTable) AllowedAt(depth int) bool {
+	return depth >= t.MinDepth && (t.MaxDepth == 0 || depth <= t.MaxDepth)
+}
+
+// Pick chooses an entry, weighted by each entry's Weight.
+func (t SpawnTable) Pick(rng utils.RNG) SpawnEntry {
+	total := 0
+	for _, e := range t.Entries {
+		total += e.Weight
+	}
+
+	roll := rng.GetDiceRoll(total)
+	for _, e := range t.Entries {
+		roll -= e.Weight
+		if roll <= 0 {
+			return e
+		}
+	}
+	return t.Entries[len(t.Entries)-1]
+}
+
+// SpawnTableFor returns the spawn table for depth. Below the deepest table,
+// the deepest one keeps being used.
+func (r *Registry) SpawnTableFor(depth int) (SpawnTable, bool) {
+	deepest := -1
+	for i, t := range r.SpawnTables {
+		if t.AllowedAt(depth) {
+			return t, true
+		}
+		if t.MinDepth <= depth && (deepest == -1 || t.MinDepth > r.SpawnTables[deepest].MinDepth) {
+			deepest = i
+		}
+	}
+	if deepest == -1 {
+		return SpawnTable{}, false
+	}
+	return r.SpawnTables[deepest], true
+}
+
+// validateSpawnTables checks the spawn tables against the monsters.
+func (r *Registry) validateSpawnTables() []error {
+	var errs []error
+	for i, t := range r.SpawnTables {
+		fail := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf("%s: table %d: %s", SpawnsFile, i+1, fmt.Sprintf(format, args...)))
+		}
+
+		if t.MinDepth < 1 {
+			fail("min_depth is %d, must be at least 1", t.MinDepth)
+		}
+		if t.MaxDepth != 0 && t.MaxDepth < t.MinDepth {
+			fail("max_depth %d is shallower than min_depth %d", t.MaxDepth, t.MinDepth)
+		}
+		if t.MinPerRoom < 0 || t.MaxPerRoom < t.MinPerRoom {
+			fail("min_per_room %d and max_per_room %d are not a valid range", t.MinPerRoom, t.MaxPerRoom)
+		}
+		if t.MinItems < 0 || t.MaxItems < t.MinItems {
+			fail("min_items %d and max_items %d are not a valid range", t.MinItems, t.MaxItems)
+		}
+		if len(t.Entries) == 0 {
+			fail("has no entries")
+		}
+
+		for _, e := range t.Entries {
+			if _, ok := r.FindMonster(e.Monster); !ok {
+				fail("monster %q is not in %s", e.Monster, MonstersFile)
+			}
+			if e.Weight < 1 {
+				fail("monster %q: weight is %d, must be at least 1", e.Monster, e.Weight)
+			}
+			if e.PackMin < 1 || e.PackMax < e.PackMin {
+				fail("monster %q: pack_min %d and pack_max %d are not a valid range", e.Monster, e.PackMin, e.PackMax)
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,396 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/caustin/rrogue/utils"
+)
+
+// The files Load reads from the data directory.
+const (
+	MonstersFile = "monsters.json"
+	WeaponsFile  = "weapons.json"
+	ArmorFile    = "armor.json"
+)
+
+// Rarity is how often something turns up compared to the other things that
+// could appear at the same depth.
+type Rarity string
+
+const (
+	Common   Rarity = "common"
+	Uncommon Rarity = "uncommon"
+	Rare     Rarity = "rare"
+)
+
+var rarityWeights = map[Rarity]int{
+	Common:   100,
+	Uncommon: 40,
+	Rare:     10,
+}
+
+// Weight returns the rarity's weight for random picks, or 0 if it isn't a
+// known rarity.
+func (r Rarity) Weight() int {
+	return rarityWeights[r]
+}
+
+// Spawn says where in the dungeon a template can appear and how often.
+// MaxDepth 0 means there is no deepest level.
+type Spawn struct {
+	MinDepth int    `json:"min_depth"`
+	MaxDepth int    `json:"max_depth,omitempty"`
+	Rarity   Rarity `json:"rarity"`
+}
+
+// AllowedAt reports whether the template can appear at depth.
+func (s Spawn) AllowedAt(depth int) bool {
+	return depth >= s.MinDepth && (s.MaxDepth == 0 || depth <= s.MaxDepth)
+}
+
+// MonsterTemplate describes a kind of monster. Weapon and Armor are the IDs
+// of the weapon and armor templates it is equipped with. Corpse is the
+// sprite of the body it leaves where it dies, if it leaves one, and Loot
+// what it drops there. XP is the experience the player earns for killing
+// it.
+type MonsterTemplate struct {
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	Sprite string    `json:"sprite"`
+	Health int       `json:"health"`
+	XP     int       `json:"xp"`
+	Weapon string    `json:"weapon"`
+	Armor  string    `json:"armor"`
+	Corpse string    `json:"corpse,omitempty"`
+	Loot   LootTable `json:"loot,omitempty"`
+	Spawn
+}
+
+// WeaponTemplate describes a melee weapon. Damage is dice notation.
+type WeaponTemplate struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Sprite     string `json:"sprite,omitempty"`
+	Damage     string `json:"damage"`
+	ToHitBonus int    `json:"to_hit_bonus"`
+	Spawn
+}
+
+// ArmorTemplate describes something worn for protection. Defense is dice
+// notation for the damage absorbed from each hit. Slot is where it is worn,
+// one of body, head, shield or ring; it defaults to body.
+type ArmorTemplate struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Sprite     string `json:"sprite,omitempty"`
+	Slot       string `json:"slot,omitempty"`
+	Defense    string `json:"defense"`
+	ArmorClass int    `json:"armor_class"`
+	Spawn
+}
+
+// armorSlots are the slots armor can be worn in.
+var armorSlots = map[string]bool{"body": true, "head": true, "shield": true, "ring": true}
+
+// WornOn returns the slot the armor is worn in.
+func (a ArmorTemplate) WornOn() string {
+	if a.Slot == "" {
+		return "body"
+	}
+	return a.Slot
+}
+
+// Registry holds every template and spawn table, in the order they were
+// defined.
+type Registry struct {
+	Monsters    []MonsterTemplate
+	Weapons     []WeaponTemplate
+	Armor       []ArmorTemplate
+	Consumables []ConsumableTemplate
+	SpawnTables []SpawnTable
+}
+
+// Load reads and validates the template files in dir. Sprites are paths
+// relative to the working directory, like every other asset, and must exist.
+func Load(dir string) (*Registry, error) {
+	files := make(map[string][]byte)
+	for _, name := range []string{MonstersFile, WeaponsFile, ArmorFile, ConsumablesFile, SpawnsFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+
+	r, err := Parse(files[MonstersFile], files[WeaponsFile], files[ArmorFile], files[ConsumablesFile], files[SpawnsFile])
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, sprite := range r.Sprites() {
+		if _, err := os.Stat(sprite); err != nil {
+			errs = append(errs, fmt.Errorf("sprite %s: %w", sprite, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return r, nil
+}
+
+// Parse decodes the JSON for each kind of template and the spawn tables, and
+// validates the result. Every problem found is reported, not just the first.
+func Parse(monsters []byte, weapons []byte, armor []byte, consumables []byte, spawns []byte) (*Registry, error) {
+	r := &Registry{}
+	if err := decode(MonstersFile, monsters, &r.Monsters); err != nil {
+		return nil, err
+	}
+	if err := decode(WeaponsFile, weapons, &r.Weapons); err != nil {
+		return nil, err
+	}
+	if err := decode(ArmorFile, armor, &r.Armor); err != nil {
+		return nil, err
+	}
+	if err := decode(ConsumablesFile, consumables, &r.Consumables); err != nil {
+		return nil, err
+	}
+	if err := decode(SpawnsFile, spawns, &r.SpawnTables); err != nil {
+		return nil, err
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// decode unmarshals a template file, rejecting fields that aren't part of
+// the template so typos don't silently fall back to zero values.
+func decode(name string, data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// Validate checks every template and spawn table, returning all the
+// problems found.
+func (r *Registry) Validate() error {
+	var errs []error
+	fail := func(file string, kind string, id string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s %q: %s", file, kind, id, fmt.Sprintf(format, args...)))
+	}
+
+	// Weapons, armor and consumables share IDs, since any of them can lie on
+	// the floor as an item
+	seen := make(map[string]bool)
+	for _, w := range r.Weapons {
+		for _, problem := range checkCommon(w.ID, w.Name, w.Spawn, seen) {
+			fail(WeaponsFile, "weapon", w.ID, "%s", problem)
+		}
+		if _, err := utils.ParseDice(w.Damage); err != nil {
+			fail(WeaponsFile, "weapon", w.ID, "damage: %v", err)
+		}
+	}
+
+	for _, a := range r.Armor {
+		for _, problem := range checkCommon(a.ID, a.Name, a.Spawn, seen) {
+			fail(ArmorFile, "armor", a.ID, "%s", problem)
+		}
+		if !armorSlots[a.WornOn()] {
+			fail(ArmorFile, "armor", a.ID, "slot %q must be body, head, shield or ring", a.Slot)
+		}
+		if _, err := utils.ParseDice(a.Defense); err != nil {
+			fail(ArmorFile, "armor", a.ID, "defense: %v", err)
+		}
+		if a.ArmorClass < 0 {
+			fail(ArmorFile, "armor", a.ID, "armor_class is %d, must not be negative", a.ArmorClass)
+		}
+	}
+	errs = append(errs, r.validateConsumables(seen)...)
+
+	seen = make(map[string]bool)
+	for _, m := range r.Monsters {
+		for _, problem := range checkCommon(m.ID, m.Name, m.Spawn, seen) {
+			fail(MonstersFile, "monster", m.ID, "%s", problem)
+		}
+		if m.Sprite == "" {
+			fail(MonstersFile, "monster", m.ID, "sprite is missing")
+		}
+		if m.Health < 1 {
+			fail(MonstersFile, "monster", m.ID, "health is %d, must be at least 1", m.Health)
+		}
+		if m.XP < 0 {
+			fail(MonstersFile, "monster", m.ID, "xp is %d, must not be negative", m.XP)
+		}
+		if _, ok := r.FindWeapon(m.Weapon); !ok {
+			fail(MonstersFile, "monster", m.ID, "weapon %q is not in %s", m.Weapon, WeaponsFile)
+		}
+		if a, ok := r.FindArmor(m.Armor); !ok {
+			fail(MonstersFile, "monster", m.ID, "armor %q is not in %s", m.Armor, ArmorFile)
+		} else if a.WornOn() != "body" {
+			fail(MonstersFile, "monster", m.ID, "armor %q is worn on the %s, not the body", m.Armor, a.WornOn())
+		}
+		for _, problem := range r.validateLoot(m.Loot) {
+			fail(MonstersFile, "monster", m.ID, "%s", problem)
+		}
+	}
+
+	errs = append(errs, r.validateSpawnTables()...)
+	return errors.Join(errs...)
+}
+
+// checkCommon checks the fields every kind of template has, recording the
+// ID in seen to catch duplicates.
+func checkCommon(id string, name string, spawn Spawn, seen map[string]bool) []string {
+	var problems []string
+	if id == "" {
+		problems = append(problems, "id is missing")
+	} else if seen[id] {
+		problems = append(problems, "id is defined more than once")
+	} else {
+		seen[id] = true
+	}
+
+	if name == "" {
+		problems = append(problems, "name is missing")
+	}
+	if spawn.MinDepth < 1 {
+		problems = append(problems, fmt.Sprintf("min_depth is %d, must be at least 1", spawn.MinDepth))
+	}
+	if spawn.MaxDepth != 0 && spawn.MaxDepth < spawn.MinDepth {
+		problems = append(problems, fmt.Sprintf("max_depth %d is shallower than min_depth %d", spawn.MaxDepth, spawn.MinDepth))
+	}
+	if spawn.Rarity.Weight() == 0 {
+		problems = append(problems, fmt.Sprintf("rarity %q must be common, uncommon or rare", spawn.Rarity))
+	}
+	return problems
+}
+
+// FindMonster returns the monster template with the given ID.
+func (r *Registry) FindMonster(id string) (MonsterTemplate, bool) {
+	for _, m := range r.Monsters {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return MonsterTemplate{}, false
+}
+
+// FindWeapon returns the weapon template with the given ID.
+func (r *Registry) FindWeapon(id string) (WeaponTemplate, bool) {
+	for _, w := range r.Weapons {
+		if w.ID == id {
+			return w, true
+		}
+	}
+	return WeaponTemplate{}, false
+}
+
+// FindArmor returns the armor template with the given ID.
+func (r *Registry) FindArmor(id string) (ArmorTemplate, bool) {
+	for _, a := range r.Armor {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return ArmorTemplate{}, false
+}
+
+// Sprites returns the sprite of every template that has one, without
+// duplicates.
+func (r *Registry) Sprites() []string {
+	var sprites []string
+	seen := make(map[string]bool)
+	add := func(sprite string) {
+		if sprite != "" && !seen[sprite] {
+			seen[sprite] = true
+			sprites = append(sprites, sprite)
+		}
+	}
+	for _, m := range r.Monsters {
+		add(m.Sprite)
+		add(m.Corpse)
+	}
+	for _, w := range r.Weapons {
+		add(w.Sprite)
+	}
+	for _, a := range r.Armor {
+		add(a.Sprite)
+	}
+	for _, c := range r.Consumables {
+		add(c.Sprite)
+	}
+	return sprites
+}
+
+// RandomMonster picks a monster that can appear at depth, weighted by
+// rarity. ok is false if no monster can appear there.
+func (r *Registry) RandomMonster(depth int, rng utils.RNG) (m MonsterTemplate, ok bool) {
+	total := 0
+	for _, m := range r.Monsters {
+		if m.AllowedAt(depth) {
+			total += m.Rarity.Weight()
+		}
+	}
+	if total == 0 {
+		return MonsterTemplate{}, false
+	}
+
+	roll := rng.GetDiceRoll(total)
+	for _, m := range r.Monsters {
+		if !m.AllowedAt(depth) {
+			continue
+		}
+		roll -= m.Rarity.Weight()
+		if roll <= 0 {
+			return m, true
+		}
+	}
+	return MonsterTemplate{}, false
+}
+
+// RandomItem picks a weapon, armor or consumable template that can appear
+// at depth, weighted by rarity, and returns its ID. ok is false if nothing can appear
+// there.
+func (r *Registry) RandomItem(depth int, rng utils.RNG) (id string, ok bool) {
+	var ids []string
+	var weights []int
+	total := 0
+	add := func(id string, spawn Spawn) {
+		if spawn.AllowedAt(depth) {
+			ids = append(ids, id)
+			weights = append(weights, spawn.Rarity.Weight())
+			total += spawn.Rarity.Weight()
+		}
+	}
+	for _, w := range r.Weapons {
+		add(w.ID, w.Spawn)
+	}
+	for _, a := range r.Armor {
+		add(a.ID, a.Spawn)
+	}
+	for _, c := range r.Consumables {
+		add(c.ID, c.Spawn)
+	}
+	if total == 0 {
+		return "", false
+	}
+
+	roll := rng.GetDiceRoll(total)
+	for i, weight := range weights {
+		roll -= weight
+		if roll <= 0 {
+			return ids[i], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,332 @@
+package templates
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/caustin/rrogue/utils"
+)
+
+const (
+	validMonsters = `[
+		{"id": "rat", "name": "Rat", "sprite": "rat.png", "health": 4, "weapon": "teeth", "armor": "fur", "min_depth": 1, "rarity": "common"},
+		{"id": "troll", "name": "Troll", "sprite": "troll.png", "health": 40, "xp": 30, "weapon": "teeth", "armor": "fur", "corpse": "corpse.png",
+			"loot": {"gold": "3d6", "drops": [{"item": "tonic", "chance": 25}]}, "min_depth": 3, "max_depth": 5, "rarity": "rare"}
+	]`
+	validWeapons     = `[{"id": "teeth", "name": "Teeth", "damage": "1d3", "to_hit_bonus": 1, "min_depth": 1, "rarity": "common"}]`
+	validArmor       = `[{"id": "fur", "name": "Fur", "defense": "1", "armor_class": 2, "min_depth": 1, "rarity": "common"}]`
+	validConsumables = `[{"id": "tonic", "name": "Tonic", "sprite": "potion.png", "kind": "potion", "effect": "heal", "power": "1d4", "min_depth": 1, "rarity": "common"}]`
+	validSpawns      = `[
+		{"min_depth": 1, "max_depth": 2, "min_per_room": 1, "max_per_room": 2, "entries": [{"monster": "rat", "weight": 1, "pack_min": 1, "pack_max": 1}]},
+		{"min_depth": 3, "max_depth": 4, "min_per_room": 2, "max_per_room": 4, "entries": [
+			{"monster": "rat", "weight": 3, "pack_min": 2, "pack_max": 3},
+			{"monster": "troll", "weight": 1, "pack_min": 1, "pack_max": 1}
+		]}
+	]`
+)
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte(validMonsters), []byte(validWeapons), []byte(validArmor), []byte(validConsumables), []byte(validSpawns))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	troll, ok := r.FindMonster("troll")
+	if !ok {
+		t.Fatal("FindMonster(troll) found nothing")
+	}
+	if troll.Health != 40 || troll.XP != 30 || troll.MaxDepth != 5 || troll.Rarity != Rare || troll.Loot.Gold != "3d6" || len(troll.Loot.Drops) != 1 {
+		t.Errorf("troll = %+v", troll)
+	}
+	if w, _ := r.FindWeapon("teeth"); w.Damage != "1d3" || w.ToHitBonus != 1 {
+		t.Errorf("teeth = %+v", w)
+	}
+	if a, _ := r.FindArmor("fur"); a.ArmorClass != 2 {
+		t.Errorf("fur = %+v", a)
+	}
+	if c, _ := r.FindConsumable("tonic"); c.Effect != EffectHeal || c.Power != "1d4" {
+		t.Errorf("tonic = %+v", c)
+	}
+	if sprites := r.Sprites(); len(sprites) != 4 {
+		t.Errorf("Sprites() = %v, expected rat.png, troll.png, corpse.png and potion.png", sprites)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		monsters    string
+		weapons     string
+		armor       string
+		consumables string
+		spawns      string
+		expected    []string
+	}{
+		{
+			name:     "malformed json",
+			monsters: `[{"id": "rat"`,
+			expected: []string{"monsters.json"},
+		},
+		{
+			name:     "unknown field",
+			weapons:  `[{"id": "teeth", "name": "Teeth", "damage": "1d3", "min_depth": 1, "rarity": "common", "dmg": "2"}]`,
+			expected: []string{"weapons.json", `unknown field "dmg"`},
+		},
+		{
+			name:     "missing weapon and armor",
+			monsters: `[{"id": "rat", "name": "Rat", "sprite": "rat.png", "health": 4, "weapon": "claws", "armor": "scales", "min_depth": 1, "rarity": "common"}]`,
+			expected: []string{`weapon "claws" is not in weapons.json`, `armor "scales" is not in armor.json`},
+		},
+		{
+			name:     "bad dice",
+			weapons:  `[{"id": "teeth", "name": "Teeth", "damage": "1d", "min_depth": 1, "rarity": "common"}]`,
+			armor:    `[{"id": "fur", "name": "Fur", "defense": "x", "armor_class": 2, "min_depth": 1, "rarity": "common"}]`,
+			expected: []string{`weapon "teeth": damage`, `armor "fur": defense`},
+		},
+		{
+			name:     "bad stats",
+			monsters: `[{"id": "rat", "name": "", "sprite": "", "health": 0, "xp": -1, "weapon": "teeth", "armor": "fur", "min_depth": 4, "max_depth": 2, "rarity": "legendary"}]`,
+			expected: []string{"name is missing", "sprite is missing", "health is 0", "xp is -1", "max_depth 2 is shallower", `rarity "legendary"`},
+		},
+		{
+			name:     "duplicate ids",
+			armor:    `[{"id": "fur", "name": "Fur", "defense": "1", "armor_class": 2, "min_depth": 1, "rarity": "common"}, {"id": "fur", "name": "Thick Fur", "defense": "2", "armor_class": -1, "min_depth": 1, "rarity": "common"}]`,
+			expected: []string{"defined more than once", "armor_class is -1"},
+		},
+		{
+			name:     "bad armor slots",
+			armor:    `[{"id": "fur", "name": "Fur", "slot": "ring", "defense": "1", "armor_class": 2, "min_depth": 1, "rarity": "common"}, {"id": "boots", "name": "Boots", "slot": "feet", "defense": "1", "armor_class": 1, "min_depth": 1, "rarity": "common"}]`,
+			expected: []string{`armor "fur" is worn on the ring, not the body`, `slot "feet" must be body, head, shield or ring`},
+		},
+		{
+			name:     "weapon and armor share an id",
+			armor:    `[{"id": "fur", "name": "Fur", "defense": "1", "armor_class": 2, "min_depth": 1, "rarity": "common"}, {"id": "teeth", "name": "Tooth Necklace", "slot": "ring", "defense": "0", "armor_class": 1, "min_depth": 1, "rarity": "common"}]`,
+			expected: []string{`armor "teeth": id is defined more than once`},
+		},
+		{
+			name:        "bad consumables",
+			consumables: `[{"id": "fur", "name": "Fizz", "kind": "wand", "effect": "heal", "power": "1d", "min_depth": 1, "rarity": "common"}, {"id": "blast", "name": "Blast", "sprite": "scroll.png", "kind": "scroll", "effect": "fire", "power": "2d4", "min_depth": 1, "rarity": "common"}, {"id": "wish", "name": "Wish", "sprite": "scroll.png", "kind": "scroll", "effect": "wish", "min_depth": 1, "rarity": "rare"}]`,
+			expected:    []string{`consumable "fur": id is defined more than once`, "sprite is missing", `kind "wand"`, `consumable "fur": power`, `consumable "blast": radius is 0`, `effect "wish"`},
+		},
+		{
+			name:        "too many potions",
+			consumables: `[` + strings.Repeat(`{"id": "", "name": "Tonic", "sprite": "potion.png", "kind": "potion", "effect": "heal", "power": "1", "min_depth": 1, "rarity": "common"},`, 8) + `{"id": "last", "name": "Tonic", "sprite": "potion.png", "kind": "potion", "effect": "heal", "power": "1", "min_depth": 1, "rarity": "common"}]`,
+			expected:    []string{`consumable "last": there are only 8 potion appearances`},
+		},
+		{
+			name:     "bad loot",
+			monsters: `[{"id": "rat", "name": "Rat", "sprite": "rat.png", "health": 4, "weapon": "teeth", "armor": "fur", "loot": {"gold": "d", "drops": [{"item": "teeth", "chance": 50}, {"item": "tonic", "chance": 0}]}, "min_depth": 1, "rarity": "common"}]`,
+			expected: []string{"loot gold", `loot item "teeth" is not a weapon, armor or consumable with a sprite`, `loot chance for "tonic" is 0`},
+		},
+		{
+			name:     "bad spawn table",
+			spawns:   `[{"min_depth": 0, "min_per_room": 3, "max_per_room": 1, "min_items": 2, "max_items": 1, "entries": [{"monster": "bat", "weight": 0, "pack_min": 2, "pack_max": 1}]}]`,
+			expected: []string{"spawns.json: table 1", "min_depth is 0", "max_per_room 1", "max_items 1", `monster "bat" is not in monsters.json`, "weight is 0", "pack_max 1"},
+		},
+		{
+			name:     "empty spawn table",
+			spawns:   `[{"min_depth": 1, "min_per_room": 1, "max_per_room": 1, "entries": []}]`,
+			expected: []string{"has no entries"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monsters, weapons, armor, consumables, spawns := validMonsters, validWeapons, validArmor, validConsumables, validSpawns
+			if tt.monsters != "" {
+				monsters = tt.monsters
+			}
+			if tt.weapons != "" {
+				weapons = tt.weapons
+			}
+			if tt.armor != "" {
+				armor = tt.armor
+			}
+			if tt.consumables != "" {
+				consumables = tt.consumables
+			}
+			if tt.spawns != "" {
+				spawns = tt.spawns
+			}
+
+			_, err := Parse([]byte(monsters), []byte(weapons), []byte(armor), []byte(consumables), []byte(spawns))
+			if err == nil {
+				t.Fatal("Parse expected an error")
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	// Sprite paths are relative to the repository root, like the game's
+	if err := os.Chdir(".."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("templates")
+
+	r, err := Load("assets/data")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	for _, id := range []string{"orc", "skeleton"} {
+		if _, ok := r.FindMonster(id); !ok {
+			t.Errorf("monster %s is not defined", id)
+		}
+	}
+}
+
+func TestRandomMonster(t *testing.T) {
+	r, err := Parse([]byte(validMonsters), []byte(validWeapons), []byte(validArmor), []byte(validConsumables), []byte(validSpawns))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	rng := utils.NewSeededRNG(3)
+
+	counts := make(map[string]int)
+	for i := 0; i < 1100; i++ {
+		m, ok := r.RandomMonster(4, rng)
+		if !ok {
+			t.Fatal("RandomMonster found nothing at depth 4")
+		}
+		counts[m.ID]++
+	}
+	// Common is ten times as likely as rare
+	if counts["troll"] < 50 || counts["troll"] > 150 {
+		t.Errorf("troll picked %d times out of 1100, expected about 100", counts["troll"])
+	}
+
+	for i := 0; i < 100; i++ {
+		if m, _ := r.RandomMonster(1, rng); m.ID == "troll" {
+			t.Fatal("troll picked above its min_depth")
+		}
+		if m, _ := r.RandomMonster(6, rng); m.ID == "troll" {
+			t.Fatal("troll picked below its max_depth")
+		}
+	}
+
+	empty := &Registry{}
+	if _, ok := empty.RandomMonster(1, rng); ok {
+		t.Error("RandomMonster with no monsters should find nothing")
+	}
+}
+
+func TestRandomItem(t *testing.T) {
+	r, err := Parse([]byte(validMonsters), []byte(validWeapons), []byte(`[
+		{"id": "fur", "name": "Fur", "defense": "1", "armor_class": 2, "min_depth": 1, "rarity": "common"},
+		{"id": "crown", "name": "Crown", "slot": "head", "defense": "0", "armor_class": 1, "min_depth": 3, "rarity": "rare"}
+	]`), []byte(validConsumables), []byte(validSpawns))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	rng := utils.NewSeededRNG(7)
+
+	counts := make(map[string]int)
+	for i := 0; i < 3100; i++ {
+		id, ok := r.RandomItem(3, rng)
+		if !ok {
+			t.Fatal("RandomItem found nothing at depth 3")
+		}
+		counts[id]++
+	}
+	// Weapons, armor and consumables are picked from together, common ten
+	// times as often as rare
+	if counts["teeth"] < 850 || counts["fur"] < 850 || counts["tonic"] < 850 || counts["crown"] < 50 || counts["crown"] > 150 {
+		t.Errorf("RandomItem picked %v out of 3100, expected about 1000 teeth, fur and tonics and 100 crowns", counts)
+	}
+	for i := 0; i < 100; i++ {
+		if id, _ := r.RandomItem(1, rng); id == "crown" {
+			t.Fatal("crown picked above its min_depth")
+		}
+	}
+
+	empty := &Registry{}
+	if _, ok := empty.RandomItem(1, rng); ok {
+		t.Error("RandomItem with no items should find nothing")
+	}
+}
+
+func TestSpawnTableFor(t *testing.T) {
+	r, err := Parse([]byte(validMonsters), []byte(validWeapons), []byte(validArmor), []byte(validConsumables), []byte(validSpawns))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	tests := []struct {
+		depth    int
+		minDepth int
+	}{
+		{depth: 1, minDepth: 1},
+		{depth: 2, minDepth: 1},
+		{depth: 3, minDepth: 3},
+		{depth: 4, minDepth: 3},
+		{depth: 9, minDepth: 3},
+	}
+	for _, tt := range tests {
+		table, ok := r.SpawnTableFor(tt.depth)
+		if !ok || table.MinDepth != tt.minDepth {
+			t.Errorf("SpawnTableFor(%d) = table from depth %d, %v, expected depth %d", tt.depth, table.MinDepth, ok, tt.minDepth)
+		}
+	}
+
+	empty := &Registry{}
+	if _, ok := empty.SpawnTableFor(1); ok {
+		t.Error("SpawnTableFor with no tables should find nothing")
+	}
+}
+
+func TestSpawnTablePick(t *testing.T) {
+	r, err := Parse([]byte(validMonsters), []byte(validWeapons), []byte(validArmor), []byte(validConsumables), []byte(validSpawns))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	table, _ := r.SpawnTableFor(3)
+	rng := utils.NewSeededRNG(5)
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[table.Pick(rng).Monster]++
+	}
+	// Rats are weighted three to one against trolls
+	if counts["troll"] < 200 || counts["troll"] > 300 {
+		t.Errorf("troll picked %d times out of 1000, expected about 250", counts["troll"])
+	}
+}
+
+func TestShuffleAppearances(t *testing.T) {
+	r, err := Parse([]byte(validMonsters), []byte(validWeapons), []byte(validArmor), []byte(`[
+		{"id": "tonic", "name": "Tonic", "sprite": "potion.png", "kind": "potion", "effect": "heal", "power": "1d4", "min_depth": 1, "rarity": "common"},
+		{"id": "elixir", "name": "Elixir", "sprite": "potion.png", "kind": "potion", "effect": "heal", "power": "2d4", "min_depth": 1, "rarity": "rare"},
+		{"id": "map", "name": "Map", "sprite": "scroll.png", "kind": "scroll", "effect": "mapping", "min_depth": 1, "rarity": "common"}
+	]`), []byte(validSpawns))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	looks := r.ShuffleAppearances(utils.NewSeededRNG(5))
+	if len(looks) != 3 || looks["tonic"] == looks["elixir"] {
+		t.Fatalf("ShuffleAppearances = %v, expected a different look for each of 3 templates", looks)
+	}
+	if !strings.HasSuffix(looks["tonic"], "Potion") || !strings.HasPrefix(looks["map"], "Scroll") {
+		t.Errorf("ShuffleAppearances = %v, expected potions to look like potions and scrolls like scrolls", looks)
+	}
+	again := r.ShuffleAppearances(utils.NewSeededRNG(5))
+	for id, look := range looks {
+		if again[id] != look {
+			t.Errorf("%s looks like %q, then %q with the same seed", id, look, again[id])
+		}
+	}
+
+	differs := false
+	for seed := int64(6); seed < 16 && !differs; seed++ {
+		differs = r.ShuffleAppearances(utils.NewSeededRNG(seed))["tonic"] != looks["tonic"]
+	}
+	if !differs {
+		t.Error("tonic looks the same for every seed")
+	}
+}
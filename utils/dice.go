@@ -1,25 +1,20 @@
 package utils
 
-import (
-	"crypto/rand"
-	"math/big"
-)
+// defaultRNG backs the package level helpers for code that has no RNG of its
+// own to draw from. Anything that should be reproducible must be given an RNG.
+var defaultRNG RNG = NewSeededRNG(NewSeed())
 
 // GetRandomBetween returns a number between the two numbers inclusive.
 func GetRandomBetween(low int, high int) int {
-	return GetDiceRoll(high-low+1) + low - 1
+	return defaultRNG.GetRandomBetween(low, high)
 }
 
 // GetRandomInt returns an integer from 0 to the number - 1
 func GetRandomInt(num int) int {
-	x, _ := rand.Int(rand.Reader, big.NewInt(int64(num)))
-	return int(x.Int64())
-
+	return defaultRNG.GetRandomInt(num)
 }
 
 // GetDiceRoll returns an integer from 1 to the number
 func GetDiceRoll(num int) int {
-	x, _ := rand.Int(rand.Reader, big.NewInt(int64(num)))
-	return int(x.Int64()) + 1
-
+	return defaultRNG.GetDiceRoll(num)
 }
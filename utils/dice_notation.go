@@ -0,0 +1,353 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxExplosions caps how many extra dice a single exploding die can add so a
+// long run of maximum rolls can never hang the game.
+const maxExplosions = 100
+
+// maxDiceCount caps how many dice a single term can roll, so a typo such as
+// "1000000000d6" in a template is rejected rather than hanging the game.
+const maxDiceCount = 1000
+
+// DiceTerm is a single part of a dice expression: either a group of dice such
+// as "4d6kh3" or a flat modifier such as "+3".
+type DiceTerm struct {
+	Count       int  // Number of dice rolled
+	Sides       int  // Sides per die; 0 means the term is a flat modifier
+	Modifier    int  // Value of a flat modifier term
+	Keep        int  // Number of dice kept; 0 keeps them all
+	KeepHighest bool // Keep the highest dice rather than the lowest
+	Exploding   bool // Roll again and add whenever a die shows its maximum
+	Negative    bool // The term is subtracted from the total
+}
+
+// DiceExpression is a parsed dice notation string such as "2d6+3".
+type DiceExpression struct {
+	Terms  []DiceTerm
+	source string
+}
+
+// TermResult records how a single term of an expression was rolled.
+type TermResult struct {
+	Term  DiceTerm
+	Rolls []int // Every die rolled, including explosions
+	Kept  []int // The dice that counted towards the total
+	Total int   // Signed contribution of the term to the expression total
+}
+
+// DiceResult is the outcome of rolling a dice expression, including the
+// individual results that make up the total.
+type DiceResult struct {
+	Expression string
+	Terms      []TermResult
+	Total      int
+}
+
+// ParseDice parses dice notation. It supports several terms joined with + and
+// -, flat modifiers, keep highest/lowest (4d6kh3, 2d20kl1) and exploding dice
+// (3d6!). An exploding term that also keeps dice may be written either way
+// round, 4d6!kh3 or 4d6kh3!. A die count may be omitted, so "d20" is the
+// same as "1d20", and a term rolls at most 1000 dice.
+func ParseDice(expr string) (DiceExpression, error) {
+	source := strings.ToLower(strings.Join(strings.Fields(expr), ""))
+	if source == "" {
+		return DiceExpression{}, fmt.Errorf("empty dice expression")
+	}
+
+	d := DiceExpression{source: source}
+	rest := source
+	for len(rest) > 0 {
+		negative := false
+		if rest[0] == '+' || rest[0] == '-' {
+			negative = rest[0] == '-'
+			rest = rest[1:]
+		} else if len(d.Terms) > 0 {
+			return DiceExpression{}, fmt.Errorf("dice expression %q: expected + or - before %q", expr, rest)
+		}
+
+		end := strings.IndexAny(rest, "+-")
+		if end == -1 {
+			end = len(rest)
+		}
+
+		term, err := parseDiceTerm(rest[:end])
+		if err != nil {
+			return DiceExpression{}, fmt.Errorf("dice expression %q: %w", expr, err)
+		}
+		term.Negative = negative
+		d.Terms = append(d.Terms, term)
+		rest = rest[end:]
+	}
+
+	return d, nil
+}
+
+// MustParseDice is like ParseDice but panics if the expression is invalid.
+// It is intended for expressions that are constants in the code.
+func MustParseDice(expr string) DiceExpression {
+	d, err := ParseDice(expr)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// RollDice parses and rolls a dice expression in one step.
+func RollDice(rng RNG, expr string) (DiceResult, error) {
+	d, err := ParseDice(expr)
+	if err != nil {
+		return DiceResult{}, err
+	}
+	return d.Roll(rng), nil
+}
+
+func parseDiceTerm(s string) (DiceTerm, error) {
+	if s == "" {
+		return DiceTerm{}, fmt.Errorf("missing term")
+	}
+
+	dIndex := strings.IndexByte(s, 'd')
+	if dIndex == -1 {
+		modifier, err := strconv.Atoi(s)
+		if err != nil {
+			return DiceTerm{}, fmt.Errorf("invalid modifier %q", s)
+		}
+		return DiceTerm{Modifier: modifier}, nil
+	}
+
+	term := DiceTerm{Count: 1}
+	if dIndex > 0 {
+		count, err := strconv.Atoi(s[:dIndex])
+		if err != nil || count < 1 {
+			return DiceTerm{}, fmt.Errorf("invalid dice count in %q", s)
+		}
+		if count > maxDiceCount {
+			return DiceTerm{}, fmt.Errorf("dice count in %q is more than %d", s, maxDiceCount)
+		}
+		term.Count = count
+	}
+
+	// The sides run up to the explode flag or the keep, whichever comes first
+	rest := s[dIndex+1:]
+	sides := rest
+	if i := strings.IndexAny(rest, "!k"); i != -1 {
+		sides, rest = rest[:i], rest[i:]
+	} else {
+		rest = ""
+	}
+	if strings.HasPrefix(rest, "!") {
+		term.Exploding = true
+		rest = rest[1:]
+	}
+
+	if strings.HasPrefix(rest, "k") {
+		keep := rest[1:]
+		if !term.Exploding && strings.HasSuffix(keep, "!") {
+			term.Exploding = true
+			keep = strings.TrimSuffix(keep, "!")
+		}
+		switch {
+		case strings.HasPrefix(keep, "h"):
+			term.KeepHighest = true
+			keep = keep[1:]
+		case strings.HasPrefix(keep, "l"):
+			keep = keep[1:]
+		default:
+			return DiceTerm{}, fmt.Errorf("keep in %q must be kh or kl", s)
+		}
+		n, err := strconv.Atoi(keep)
+		if err != nil || n < 1 || n > term.Count {
+			return DiceTerm{}, fmt.Errorf("invalid keep count in %q", s)
+		}
+		term.Keep = n
+	} else if rest != "" {
+		return DiceTerm{}, fmt.Errorf("unexpected %q in %q", rest, s)
+	}
+
+	n, err := strconv.Atoi(sides)
+	if err != nil || n < 1 {
+		return DiceTerm{}, fmt.Errorf("invalid number of sides in %q", s)
+	}
+	term.Sides = n
+
+	if term.Exploding && term.Sides == 1 {
+		return DiceTerm{}, fmt.Errorf("a one sided die cannot explode in %q", s)
+	}
+
+	return term, nil
+}
+
+// Roll rolls every term of the expression.
+func (d DiceExpression) Roll(rng RNG) DiceResult {
+	result := DiceResult{Expression: d.String()}
+
+	for _, term := range d.Terms {
+		tr := term.roll(rng)
+		result.Terms = append(result.Terms, tr)
+		result.Total += tr.Total
+	}
+
+	return result
+}
+
+func (t DiceTerm) roll(rng RNG) TermResult {
+	tr := TermResult{Term: t}
+
+	if t.Sides == 0 {
+		tr.Total = t.Modifier
+	} else {
+		for i := 0; i < t.Count; i++ {
+			roll := rng.GetDiceRoll(t.Sides)
+			value := roll
+			for n := 0; t.Exploding && roll == t.Sides && n < maxExplosions; n++ {
+				tr.Rolls = append(tr.Rolls, roll)
+				roll = rng.GetDiceRoll(t.Sides)
+				value += roll
+			}
+			tr.Rolls = append(tr.Rolls, roll)
+			tr.Kept = append(tr.Kept, value)
+		}
+
+		if t.Keep > 0 {
+			tr.Kept = keepDice(tr.Kept, t.Keep, t.KeepHighest)
+		}
+
+		for _, v := range tr.Kept {
+			tr.Total += v
+		}
+	}
+
+	if t.Negative {
+		tr.Total = -tr.Total
+	}
+	return tr
+}
+
+// keepDice returns the n highest or lowest values, preserving roll order.
+func keepDice(values []int, n int, highest bool) []int {
+	dropped := make([]bool, len(values))
+	for drop := len(values) - n; drop > 0; drop-- {
+		worst := -1
+		for i, v := range values {
+			if dropped[i] {
+				continue
+			}
+			if worst == -1 || (highest && v < values[worst]) || (!highest && v > values[worst]) {
+				worst = i
+			}
+		}
+		dropped[worst] = true
+	}
+
+	kept := make([]int, 0, n)
+	for i, v := range values {
+		if !dropped[i] {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// Min returns the lowest total the expression can roll.
+func (d DiceExpression) Min() int {
+	total := 0
+	for _, t := range d.Terms {
+		total += t.bound(!t.Negative)
+	}
+	return total
+}
+
+// Max returns the highest total the expression can roll, ignoring explosions.
+func (d DiceExpression) Max() int {
+	total := 0
+	for _, t := range d.Terms {
+		total += t.bound(t.Negative)
+	}
+	return total
+}
+
+// bound returns the signed lowest (low == true) or highest value of the term.
+func (t DiceTerm) bound(low bool) int {
+	value := t.Modifier
+	if t.Sides > 0 {
+		dice := t.Count
+		if t.Keep > 0 {
+			dice = t.Keep
+		}
+		value = dice * t.Sides
+		if low {
+			value = dice
+		}
+	}
+	if t.Negative {
+		return -value
+	}
+	return value
+}
+
+// String returns the normalized notation of the expression.
+func (d DiceExpression) String() string {
+	if d.source != "" {
+		return d.source
+	}
+
+	var sb strings.Builder
+	for i, t := range d.Terms {
+		if t.Negative {
+			sb.WriteString("-")
+		} else if i > 0 {
+			sb.WriteString("+")
+		}
+		sb.WriteString(t.String())
+	}
+	return sb.String()
+}
+
+// String returns the notation of a single term without its sign.
+func (t DiceTerm) String() string {
+	if t.Sides == 0 {
+		return strconv.Itoa(t.Modifier)
+	}
+
+	s := fmt.Sprintf("%dd%d", t.Count, t.Sides)
+	if t.Keep > 0 {
+		if t.KeepHighest {
+			s += fmt.Sprintf("kh%d", t.Keep)
+		} else {
+			s += fmt.Sprintf("kl%d", t.Keep)
+		}
+	}
+	if t.Exploding {
+		s += "!"
+	}
+	return s
+}
+
+// String returns a breakdown of the roll, e.g. "2d6+3: [4 2]+3 = 9".
+func (r DiceResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Expression)
+	sb.WriteString(": ")
+	for i, tr := range r.Terms {
+		if tr.Term.Negative {
+			sb.WriteString("-")
+		} else if i > 0 {
+			sb.WriteString("+")
+		}
+		if tr.Term.Sides == 0 {
+			sb.WriteString(strconv.Itoa(tr.Term.Modifier))
+			continue
+		}
+		sb.WriteString(fmt.Sprint(tr.Rolls))
+		if len(tr.Kept) != len(tr.Rolls) {
+			sb.WriteString(fmt.Sprintf("->%v", tr.Kept))
+		}
+	}
+	sb.WriteString(fmt.Sprintf(" = %d", r.Total))
+	return sb.String()
+}
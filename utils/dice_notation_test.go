@@ -0,0 +1,197 @@
+package utils
+
+import "testing"
+
+// fixedRNG returns the queued rolls in order, for checking exact breakdowns.
+type fixedRNG struct {
+	rolls []int
+}
+
+func (f *fixedRNG) GetRandomBetween(low int, high int) int {
+	return f.GetDiceRoll(high-low+1) + low - 1
+}
+
+func (f *fixedRNG) GetRandomInt(num int) int {
+	return f.GetDiceRoll(num) - 1
+}
+
+func (f *fixedRNG) GetDiceRoll(num int) int {
+	roll := f.rolls[0]
+	f.rolls = f.rolls[1:]
+	return roll
+}
+
+func TestParseDice(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		min  int
+		max  int
+	}{
+		{name: "single die", expr: "1d20", min: 1, max: 20},
+		{name: "implicit count", expr: "d8", min: 1, max: 8},
+		{name: "dice plus modifier", expr: "2d6+3", min: 5, max: 15},
+		{name: "dice minus modifier", expr: "1d20-1", min: 0, max: 19},
+		{name: "keep highest", expr: "4d6kh3", min: 3, max: 18},
+		{name: "keep lowest", expr: "2d20kl1", min: 1, max: 20},
+		{name: "multiple dice", expr: "1d8+1d6+2", min: 4, max: 16},
+		{name: "subtracted dice", expr: "10-1d4", min: 6, max: 9},
+		{name: "flat number", expr: "15", min: 15, max: 15},
+		{name: "exploding", expr: "3d6!", min: 3, max: 18},
+		{name: "exploding then keep", expr: "4d6!kh3", min: 3, max: 18},
+		{name: "keep then exploding", expr: "4d6kh3!", min: 3, max: 18},
+		{name: "most dice", expr: "1000d2", min: 1000, max: 2000},
+		{name: "whitespace and case", expr: " 2D6 + 3 ", min: 5, max: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDice(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseDice(%q) returned error: %v", tt.expr, err)
+			}
+			if d.Min() != tt.min {
+				t.Errorf("Min() = %d, expected %d", d.Min(), tt.min)
+			}
+			if d.Max() != tt.max {
+				t.Errorf("Max() = %d, expected %d", d.Max(), tt.max)
+			}
+		})
+	}
+}
+
+func TestParseDiceExplodeAndKeepInEitherOrder(t *testing.T) {
+	first, err := ParseDice("4d6!kh3")
+	if err != nil {
+		t.Fatalf("ParseDice(4d6!kh3) returned error: %v", err)
+	}
+	second, err := ParseDice("4d6kh3!")
+	if err != nil {
+		t.Fatalf("ParseDice(4d6kh3!) returned error: %v", err)
+	}
+	want := DiceTerm{Count: 4, Sides: 6, Keep: 3, KeepHighest: true, Exploding: true}
+	if first.Terms[0] != want || second.Terms[0] != want {
+		t.Errorf("terms = %+v and %+v, expected %+v", first.Terms[0], second.Terms[0], want)
+	}
+}
+
+func TestParseDiceErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"d",
+		"2d",
+		"0d6",
+		"2d0",
+		"xd6",
+		"2d6+",
+		"2d6++3",
+		"4d6kh5",
+		"4d6kx2",
+		"4d6k",
+		"3d1!",
+		"4d6!kh3!",
+		"3d6!!",
+		"1001d6",
+		"1000000000d6",
+	}
+
+	for _, expr := range invalid {
+		if _, err := ParseDice(expr); err == nil {
+			t.Errorf("ParseDice(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestDiceRollWithinBounds(t *testing.T) {
+	rng := NewSeededRNG(7)
+	exprs := []string{"2d6+3", "1d20-1", "4d6kh3", "2d20kl1", "1d8+1d6+2", "10-1d4"}
+
+	for _, expr := range exprs {
+		d := MustParseDice(expr)
+		for i := 0; i < 500; i++ {
+			r := d.Roll(rng)
+			if r.Total < d.Min() || r.Total > d.Max() {
+				t.Errorf("%s rolled %d, outside [%d, %d]", expr, r.Total, d.Min(), d.Max())
+			}
+		}
+	}
+}
+
+func TestDiceRollBreakdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		rolls     []int
+		total     int
+		kept      []int
+		breakdown string
+	}{
+		{
+			name:      "dice plus modifier",
+			expr:      "2d6+3",
+			rolls:     []int{4, 2},
+			total:     9,
+			kept:      []int{4, 2},
+			breakdown: "2d6+3: [4 2]+3 = 9",
+		},
+		{
+			name:      "keep highest",
+			expr:      "4d6kh3",
+			rolls:     []int{1, 5, 3, 6},
+			total:     14,
+			kept:      []int{5, 3, 6},
+			breakdown: "4d6kh3: [1 5 3 6]->[5 3 6] = 14",
+		},
+		{
+			name:      "keep lowest",
+			expr:      "2d20kl1",
+			rolls:     []int{17, 4},
+			total:     4,
+			kept:      []int{4},
+			breakdown: "2d20kl1: [17 4]->[4] = 4",
+		},
+		{
+			name:      "exploding",
+			expr:      "1d6!",
+			rolls:     []int{6, 6, 2},
+			total:     14,
+			kept:      []int{14},
+			breakdown: "1d6!: [6 6 2]->[14] = 14",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := MustParseDice(tt.expr).Roll(&fixedRNG{rolls: tt.rolls})
+			if r.Total != tt.total {
+				t.Errorf("Total = %d, expected %d", r.Total, tt.total)
+			}
+			if len(r.Terms[0].Kept) != len(tt.kept) {
+				t.Fatalf("Kept = %v, expected %v", r.Terms[0].Kept, tt.kept)
+			}
+			for i := range tt.kept {
+				if r.Terms[0].Kept[i] != tt.kept[i] {
+					t.Errorf("Kept = %v, expected %v", r.Terms[0].Kept, tt.kept)
+					break
+				}
+			}
+			if r.String() != tt.breakdown {
+				t.Errorf("String() = %q, expected %q", r.String(), tt.breakdown)
+			}
+		})
+	}
+}
+
+func TestRollDice(t *testing.T) {
+	if _, err := RollDice(NewSeededRNG(1), "bogus"); err == nil {
+		t.Error("RollDice with an invalid expression expected an error")
+	}
+
+	r, err := RollDice(&fixedRNG{rolls: []int{3}}, "1d4-1")
+	if err != nil {
+		t.Fatalf("RollDice returned error: %v", err)
+	}
+	if r.Total != 2 {
+		t.Errorf("RollDice(1d4-1) = %d, expected 2", r.Total)
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import "time"
+
+// RNG is a source of random numbers. Everything that affects the outcome of a
+// run (level generation, spawning, combat) draws from an RNG so the whole run
+// can be reproduced from a single seed.
+type RNG interface {
+	// GetRandomBetween returns a number between the two numbers inclusive.
+	GetRandomBetween(low int, high int) int
+	// GetRandomInt returns an integer from 0 to the number - 1
+	GetRandomInt(num int) int
+	// GetDiceRoll returns an integer from 1 to the number
+	GetDiceRoll(num int) int
+}
+
+// SeededRNG is a deterministic RNG. Two SeededRNGs created with the same seed
+// produce the same sequence of numbers.
+//
+// The generator is SplitMix64, which keeps its whole state in a single
+// uint64 so it can be inspected and restored.
+type SeededRNG struct {
+	seed  int64
+	state uint64
+}
+
+// NewSeededRNG creates a deterministic RNG from the given seed.
+func NewSeededRNG(seed int64) *SeededRNG {
+	return &SeededRNG{
+		seed:  seed,
+		state: uint64(seed),
+	}
+}
+
+// NewSeed returns a seed derived from the current time, for runs where the
+// player did not ask for a specific one.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// Seed returns the seed the RNG was created with.
+func (r *SeededRNG) Seed() int64 {
+	return r.seed
+}
+
+// State returns the current internal state of the generator.
+func (r *SeededRNG) State() uint64 {
+	return r.state
+}
+
+// SetState restores a state previously returned by State.
+func (r *SeededRNG) SetState(state uint64) {
+	r.state = state
+}
+
+func (r *SeededRNG) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// GetRandomBetween returns a number between the two numbers inclusive.
+func (r *SeededRNG) GetRandomBetween(low int, high int) int {
+	return r.GetDiceRoll(high-low+1) + low - 1
+}
+
+// GetRandomInt returns an integer from 0 to the number - 1
+func (r *SeededRNG) GetRandomInt(num int) int {
+	if num <= 0 {
+		return 0
+	}
+	return int(r.next() % uint64(num))
+}
+
+// GetDiceRoll returns an integer from 1 to the number
+func (r *SeededRNG) GetDiceRoll(num int) int {
+	return r.GetRandomInt(num) + 1
+}
@@ -0,0 +1,68 @@
+package utils
+
+import "testing"
+
+func TestSeededRNGIsDeterministic(t *testing.T) {
+	a := NewSeededRNG(12345)
+	b := NewSeededRNG(12345)
+
+	for i := 0; i < 1000; i++ {
+		x := a.GetRandomInt(1000)
+		y := b.GetRandomInt(1000)
+		if x != y {
+			t.Fatalf("roll %d differed between RNGs with the same seed: %d != %d", i, x, y)
+		}
+	}
+}
+
+func TestSeededRNGDifferentSeeds(t *testing.T) {
+	a := NewSeededRNG(1)
+	b := NewSeededRNG(2)
+
+	same := 0
+	for i := 0; i < 100; i++ {
+		if a.GetRandomInt(1000000) == b.GetRandomInt(1000000) {
+			same++
+		}
+	}
+
+	if same == 100 {
+		t.Error("RNGs with different seeds produced identical sequences")
+	}
+}
+
+func TestSeededRNGRanges(t *testing.T) {
+	rng := NewSeededRNG(42)
+
+	for i := 0; i < 1000; i++ {
+		if roll := rng.GetDiceRoll(6); roll < 1 || roll > 6 {
+			t.Errorf("GetDiceRoll(6) returned %d, expected 1-6", roll)
+		}
+		if n := rng.GetRandomInt(10); n < 0 || n >= 10 {
+			t.Errorf("GetRandomInt(10) returned %d, expected 0-9", n)
+		}
+		if n := rng.GetRandomBetween(3, 7); n < 3 || n > 7 {
+			t.Errorf("GetRandomBetween(3, 7) returned %d, expected 3-7", n)
+		}
+	}
+}
+
+func TestSeededRNGStateRestore(t *testing.T) {
+	rng := NewSeededRNG(99)
+	rng.GetRandomInt(100)
+	rng.GetRandomInt(100)
+
+	state := rng.State()
+	expected := []int{rng.GetRandomInt(100), rng.GetRandomInt(100), rng.GetRandomInt(100)}
+
+	rng.SetState(state)
+	for i, want := range expected {
+		if got := rng.GetRandomInt(100); got != want {
+			t.Errorf("roll %d after SetState = %d, expected %d", i, got, want)
+		}
+	}
+
+	if rng.Seed() != 99 {
+		t.Errorf("Seed() = %d, expected 99", rng.Seed())
+	}
+}
@@ -2,18 +2,37 @@ package world
 
 import (
 	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/assets"
 	"github.com/caustin/rrogue/components"
 	"github.com/caustin/rrogue/level"
+	"github.com/caustin/rrogue/templates"
 	"github.com/caustin/rrogue/utils"
 	"log"
+	"sort"
 
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The player's starting equipment, by template ID, and how many items they
+// can carry
+const (
+	playerWeapon   = "battle_axe"
+	playerArmor    = "plate"
+	playerCapacity = 10
+)
+
+// Sprites for the entities that don't come from templates
+const (
+	playerSprite = "assets/player.png"
+	keySprite    = "assets/key.png"
+	goldSprite   = "assets/gold.png"
 )
 
 // ComponentReferences holds all ECS component references
 type ComponentReferences struct {
 	Position    *ecs.Component
 	Renderable  *ecs.Component
+	Movable     *ecs.Component
 	Monster     *ecs.Component
 	Health      *ecs.Component
 	MeleeWeapon *ecs.Component
@@ -21,6 +40,24 @@ type ComponentReferences struct {
 	Name        *ecs.Component
 	UserMessage *ecs.Component
 	Player      *ecs.Component
+	Depth       *ecs.Component
+	Key         *ecs.Component
+	Keyring     *ecs.Component
+	Item        *ecs.Component
+	Inventory   *ecs.Component
+	Equipment   *ecs.Component
+	Corpse      *ecs.Component
+	Gold        *ecs.Component
+	Experience  *ecs.Component
+}
+
+// all returns every component, for building query results of whole entities.
+func (cr *ComponentReferences) all() []*ecs.Component {
+	return []*ecs.Component{
+		cr.Position, cr.Renderable, cr.Movable, cr.Monster, cr.Health, cr.MeleeWeapon, cr.Armor,
+		cr.Name, cr.UserMessage, cr.Player, cr.Depth, cr.Key, cr.Keyring, cr.Item, cr.Inventory, cr.Equipment,
+		cr.Corpse, cr.Gold, cr.Experience,
+	}
 }
 
 // GameWorld implements WorldService and manages the ECS world
@@ -28,16 +65,29 @@ type GameWorld struct {
 	manager    *ecs.Manager
 	tags       map[string]ecs.Tag
 	components *ComponentReferences
+
+	// Entities on other levels are kept but only the active depth is queried
+	activeDepth int
+
+	// Every live entity by ID, and the IDs of what is on each tile
+	entities map[ecs.EntityID]*ecs.Entity
+	spatial  map[tileKey][]ecs.EntityID
+
+	// Monster, weapon and armor definitions, and every sprite by path
+	templates *templates.Registry
+	sprites   map[string]*ebiten.Image
+	assets    assets.Provider
+
+	// What potions and scrolls look like, and which have been identified
+	knowledge ItemKnowledge
 }
 
-// NewGameWorld creates a new GameWorld with initialized entities
-func NewGameWorld(startingLevel level.Level) *GameWorld {
-	manager, tags, components := initializeWorld(startingLevel)
-	return &GameWorld{
-		manager:    manager,
-		tags:       tags,
-		components: components,
-	}
+// NewGameWorld creates a new GameWorld with initialized entities, built
+// from the given templates. Sprites are loaded through provider.
+func NewGameWorld(startingLevel level.Level, rng utils.RNG, registry *templates.Registry, provider assets.Provider) *GameWorld {
+	w := &GameWorld{templates: registry, assets: provider}
+	w.initializeWorld(startingLevel, rng)
+	return w
 }
 
 // QueryPlayers returns all player entities
@@ -45,14 +95,71 @@ func (w *GameWorld) QueryPlayers() []*ecs.QueryResult {
 	return w.manager.Query(w.tags["players"])
 }
 
-// QueryMonsters returns all monster entities
+// QueryMonsters returns all monster entities on the active depth
 func (w *GameWorld) QueryMonsters() []*ecs.QueryResult {
-	return w.manager.Query(w.tags["monsters"])
+	return w.onActiveDepth(w.manager.Query(w.tags["monsters"]))
+}
+
+// QueryKeys returns all keys lying on the floor of the active depth
+func (w *GameWorld) QueryKeys() []*ecs.QueryResult {
+	return w.onActiveDepth(w.manager.Query(w.tags["keys"]))
+}
+
+// QueryGold returns all piles of gold lying on the floor of the active depth
+func (w *GameWorld) QueryGold() []*ecs.QueryResult {
+	return w.onActiveDepth(w.manager.Query(w.tags["gold"]))
 }
 
-// QueryRenderables returns all renderable entities
+// QueryItems returns all items lying on the floor of the active depth
+func (w *GameWorld) QueryItems() []*ecs.QueryResult {
+	return w.onActiveDepth(w.manager.Query(w.tags["items"]))
+}
+
+// QueryRenderables returns all renderable entities on the active depth, in
+// the order they are drawn: corpses first, then the other things lying on
+// the floor, then the creatures that may be standing on them.
 func (w *GameWorld) QueryRenderables() []*ecs.QueryResult {
-	return w.manager.Query(w.tags["renderables"])
+	results := w.onActiveDepth(w.manager.Query(w.tags["renderables"]))
+	sort.SliceStable(results, func(i, j int) bool {
+		return w.drawLayer(results[i].Entity) < w.drawLayer(results[j].Entity)
+	})
+	return results
+}
+
+// drawLayer is how high up an entity is drawn; higher layers cover lower
+// ones on the same tile.
+func (w *GameWorld) drawLayer(entity *ecs.Entity) int {
+	if _, ok := entity.GetComponentData(w.components.Health); ok {
+		return 2
+	}
+	if _, ok := entity.GetComponentData(w.components.Corpse); ok {
+		return 0
+	}
+	return 1
+}
+
+// onActiveDepth filters out entities that belong to a level other than the
+// active one. Entities without a Depth, like the player, are always kept.
+func (w *GameWorld) onActiveDepth(results []*ecs.QueryResult) []*ecs.QueryResult {
+	active := make([]*ecs.QueryResult, 0, len(results))
+	for _, result := range results {
+		data, ok := result.Entity.GetComponentData(w.components.Depth)
+		if ok && data.(*components.Depth).Level != w.activeDepth {
+			continue
+		}
+		active = append(active, result)
+	}
+	return active
+}
+
+// SetActiveDepth switches which dungeon level's entities are queried and simulated
+func (w *GameWorld) SetActiveDepth(depth int) {
+	w.activeDepth = depth
+}
+
+// GetActiveDepth returns the dungeon level whose entities are currently active
+func (w *GameWorld) GetActiveDepth() int {
+	return w.activeDepth
 }
 
 // QueryMessengers returns all entities with user messages
@@ -80,6 +187,41 @@ func (w *GameWorld) GetMeleeWeapon(entity *ecs.QueryResult) *components.MeleeWea
 	return entity.Components[w.components.MeleeWeapon].(*components.MeleeWeapon)
 }
 
+// GetKeyring returns the keyring component of an entity
+func (w *GameWorld) GetKeyring(entity *ecs.QueryResult) *components.Keyring {
+	return entity.Components[w.components.Keyring].(*components.Keyring)
+}
+
+// GetItem returns the item component of an entity
+func (w *GameWorld) GetItem(entity *ecs.QueryResult) *components.Item {
+	return entity.Components[w.components.Item].(*components.Item)
+}
+
+// GetInventory returns the inventory component of an entity
+func (w *GameWorld) GetInventory(entity *ecs.QueryResult) *components.Inventory {
+	return entity.Components[w.components.Inventory].(*components.Inventory)
+}
+
+// GetEquipment returns the equipment component of an entity
+func (w *GameWorld) GetEquipment(entity *ecs.QueryResult) *components.Equipment {
+	return entity.Components[w.components.Equipment].(*components.Equipment)
+}
+
+// GetMonster returns the monster component of an entity
+func (w *GameWorld) GetMonster(entity *ecs.QueryResult) *components.Monster {
+	return entity.Components[w.components.Monster].(*components.Monster)
+}
+
+// GetExperience returns the experience component of an entity
+func (w *GameWorld) GetExperience(entity *ecs.QueryResult) *components.Experience {
+	return entity.Components[w.components.Experience].(*components.Experience)
+}
+
+// GetGold returns the gold component of a pile of gold
+func (w *GameWorld) GetGold(entity *ecs.QueryResult) *components.Gold {
+	return entity.Components[w.components.Gold].(*components.Gold)
+}
+
 // GetName returns the name component of an entity
 func (w *GameWorld) GetName(entity *ecs.QueryResult) *components.Name {
 	return entity.Components[w.components.Name].(*components.Name)
@@ -97,6 +239,7 @@ func (w *GameWorld) GetRenderable(entity *ecs.QueryResult) *components.Renderabl
 
 // DisposeEntity removes an entity from the world
 func (w *GameWorld) DisposeEntity(entity *ecs.QueryResult) {
+	w.untrack(entity.Entity)
 	w.manager.DisposeEntity(entity.Entity)
 }
 
@@ -105,8 +248,9 @@ func (w *GameWorld) GetManager() *ecs.Manager {
 	return w.manager
 }
 
-// initializeWorld creates and populates the ECS world (moved from game package)
-func initializeWorld(startingLevel level.Level) (*ecs.Manager, map[string]ecs.Tag, *ComponentReferences) {
+// setup creates the ECS manager, components and tags, and loads every
+// sprite up front so a missing image stops the game at startup.
+func (w *GameWorld) setup() {
 	tags := make(map[string]ecs.Tag)
 	manager := ecs.NewManager()
 
@@ -115,39 +259,99 @@ func initializeWorld(startingLevel level.Level) (*ecs.Manager, map[string]ecs.Ta
 		Player:      manager.NewComponent(),
 		Position:    manager.NewComponent(),
 		Renderable:  manager.NewComponent(),
+		Movable:     manager.NewComponent(),
 		Monster:     manager.NewComponent(),
 		Health:      manager.NewComponent(),
 		MeleeWeapon: manager.NewComponent(),
 		Armor:       manager.NewComponent(),
 		Name:        manager.NewComponent(),
 		UserMessage: manager.NewComponent(),
+		Depth:       manager.NewComponent(),
+		Key:         manager.NewComponent(),
+		Keyring:     manager.NewComponent(),
+		Item:        manager.NewComponent(),
+		Inventory:   manager.NewComponent(),
+		Equipment:   manager.NewComponent(),
+		Corpse:      manager.NewComponent(),
+		Gold:        manager.NewComponent(),
+		Experience:  manager.NewComponent(),
 	}
 
-	movable := manager.NewComponent()
+	players := ecs.BuildTag(cr.Player, cr.Position, cr.Health, cr.MeleeWeapon, cr.Armor, cr.Name, cr.UserMessage, cr.Keyring, cr.Inventory, cr.Equipment, cr.Experience)
+	tags["players"] = players
 
-	playerImg, _, err := ebitenutil.NewImageFromFile("assets/player.png")
-	if err != nil {
-		log.Fatal(err)
+	renderables := ecs.BuildTag(cr.Renderable, cr.Position)
+	tags["renderables"] = renderables
+
+	monsters := ecs.BuildTag(cr.Monster, cr.Position, cr.Health, cr.MeleeWeapon, cr.Armor, cr.Name, cr.UserMessage, cr.Depth)
+	tags["monsters"] = monsters
+
+	messengers := ecs.BuildTag(cr.UserMessage)
+	tags["messengers"] = messengers
+
+	keys := ecs.BuildTag(cr.Key, cr.Position, cr.Depth)
+	tags["keys"] = keys
+
+	gold := ecs.BuildTag(cr.Gold, cr.Position, cr.Depth)
+	tags["gold"] = gold
+
+	// Carried items have no position, so only items on the floor match
+	items := ecs.BuildTag(cr.Item, cr.Name, cr.Position, cr.Depth)
+	tags["items"] = items
+
+	w.manager = manager
+	w.tags = tags
+	w.components = cr
+	w.entities = make(map[ecs.EntityID]*ecs.Entity)
+	w.spatial = make(map[tileKey][]ecs.EntityID)
+
+	w.sprites = make(map[string]*ebiten.Image)
+	for _, sprite := range append([]string{playerSprite, keySprite, goldSprite}, w.templates.Sprites()...) {
+		w.sprite(sprite)
 	}
-	skellyImg, _, err := ebitenutil.NewImageFromFile("assets/skelly.png")
-	if err != nil {
-		log.Fatal(err)
+}
+
+// sprite returns the image at path, loading it the first time it is asked for.
+func (w *GameWorld) sprite(path string) *ebiten.Image {
+	if img, ok := w.sprites[path]; ok {
+		return img
 	}
-	orcImg, _, err := ebitenutil.NewImageFromFile("assets/orc.png")
+	img, err := w.assets.Image(path)
 	if err != nil {
 		log.Fatal(err)
 	}
+	w.sprites[path] = img
+	return img
+}
+
+// initializeWorld creates and populates the ECS world (moved from game package)
+func (w *GameWorld) initializeWorld(startingLevel level.Level, rng utils.RNG) {
+	w.setup()
+	cr := w.components
+	w.knowledge = ItemKnowledge{Appearances: w.templates.ShuffleAppearances(rng)}
+
+	weapon, ok := w.newItem(playerWeapon)
+	if !ok {
+		log.Fatalf("player weapon %q is not an item", playerWeapon)
+	}
+	armor, ok := w.newItem(playerArmor)
+	if !ok {
+		log.Fatalf("player armor %q is not an item", playerArmor)
+	}
+	w.track(weapon)
+	w.track(armor)
 
 	//Get First Room
 	startingRoom := startingLevel.Rooms[0]
 	x, y := startingRoom.Center()
 
-	manager.NewEntity().
+	player := w.manager.NewEntity().
 		AddComponent(cr.Player, components.Player{}).
 		AddComponent(cr.Renderable, &components.Renderable{
-			Image: playerImg,
+			Image:  w.sprite(playerSprite),
+			Sprite: playerSprite,
 		}).
-		AddComponent(movable, components.Movable{}).
+		AddComponent(cr.Movable, components.Movable{}).
 		AddComponent(cr.Position, &components.Position{
 			X: x,
 			Y: y,
@@ -156,16 +360,19 @@ func initializeWorld(startingLevel level.Level) (*ecs.Manager, map[string]ecs.Ta
 			MaxHealth:     30,
 			CurrentHealth: 30,
 		}).
-		AddComponent(cr.MeleeWeapon, &components.MeleeWeapon{
-			Name:          "Battle Axe",
-			MinimumDamage: 10,
-			MaximumDamage: 20,
-			ToHitBonus:    3,
+		AddComponent(cr.MeleeWeapon, &components.MeleeWeapon{}).
+		AddComponent(cr.Armor, &components.Armor{}).
+		AddComponent(cr.Keyring, &components.Keyring{}).
+		AddComponent(cr.Experience, &components.Experience{Level: 1}).
+		AddComponent(cr.Inventory, &components.Inventory{
+			Items:    []ecs.EntityID{weapon.GetID(), armor.GetID()},
+			Capacity: playerCapacity,
 		}).
-		AddComponent(cr.Armor, &components.Armor{
-			Name:       "Plate Armor",
-			Defense:    15,
-			ArmorClass: 18,
+		AddComponent(cr.Equipment, &components.Equipment{
+			Slots: map[components.Slot]ecs.EntityID{
+				components.SlotWeapon: weapon.GetID(),
+				components.SlotBody:   armor.GetID(),
+			},
 		}).
 		AddComponent(cr.Name, &components.Name{Label: "Player"}).
 		AddComponent(cr.UserMessage, &components.UserMessage{
@@ -173,93 +380,187 @@ func initializeWorld(startingLevel level.Level) (*ecs.Manager, map[string]ecs.Ta
 			DeadMessage:      "",
 			GameStateMessage: "",
 		})
+	w.track(player)
+	w.ApplyEquipment(w.resultFor(player))
 
-	//Add a Monster in each room except the player's room
-	for _, room := range startingLevel.Rooms {
-		if room.X1 != startingRoom.X1 {
-			mX, mY := room.Center()
-
-			//Flip a coin to see what to add...
-			mobSpawn := utils.GetDiceRoll(2)
-
-			if mobSpawn == 1 {
-				manager.NewEntity().
-					AddComponent(cr.Monster, &components.Monster{}).
-					AddComponent(cr.Renderable, &components.Renderable{
-						Image: orcImg,
-					}).
-					AddComponent(cr.Position, &components.Position{
-						X: mX,
-						Y: mY,
-					}).
-					AddComponent(cr.Health, &components.Health{
-						MaxHealth:     30,
-						CurrentHealth: 30,
-					}).
-					AddComponent(cr.MeleeWeapon, &components.MeleeWeapon{
-						Name:          "Machete",
-						MinimumDamage: 4,
-						MaximumDamage: 8,
-						ToHitBonus:    1,
-					}).
-					AddComponent(cr.Armor, &components.Armor{
-						Name:       "Leather",
-						Defense:    5,
-						ArmorClass: 6,
-					}).
-					AddComponent(cr.Name, &components.Name{Label: "Orc"}).
-					AddComponent(cr.UserMessage, &components.UserMessage{
-						AttackMessage:    "",
-						DeadMessage:      "",
-						GameStateMessage: "",
-					})
-			} else {
-				manager.NewEntity().
-					AddComponent(cr.Monster, &components.Monster{}).
-					AddComponent(cr.Renderable, &components.Renderable{
-						Image: skellyImg,
-					}).
-					AddComponent(cr.Position, &components.Position{
-						X: mX,
-						Y: mY,
-					}).
-					AddComponent(cr.Health, &components.Health{
-						MaxHealth:     10,
-						CurrentHealth: 10,
-					}).
-					AddComponent(cr.MeleeWeapon, &components.MeleeWeapon{
-						Name:          "Short Sword",
-						MinimumDamage: 2,
-						MaximumDamage: 6,
-						ToHitBonus:    0,
-					}).
-					AddComponent(cr.Armor, &components.Armor{
-						Name:       "Bone",
-						Defense:    3,
-						ArmorClass: 4,
-					}).
-					AddComponent(cr.Name, &components.Name{Label: "Skeleton"}).
-					AddComponent(cr.UserMessage, &components.UserMessage{
-						AttackMessage:    "",
-						DeadMessage:      "",
-						GameStateMessage: "",
-					})
+	w.activeDepth = startingLevel.Depth
+
+	w.PopulateLevel(startingLevel, rng)
+}
+
+// PopulateLevel spawns the keys, items and monsters for a newly generated
+// level, along with whatever its vault spawn points ask for. Rooms are
+// filled from the spawn table for the level's depth, and monsters get
+// tougher the deeper the level is.
+func (w *GameWorld) PopulateLevel(l level.Level, rng utils.RNG) {
+	startingRoom := l.Rooms[0]
+
+	// Keys are created first so monsters standing on them are drawn on top
+	for _, pos := range l.Keys {
+		w.spawnKey(pos, l.Depth)
+	}
+	for _, spawn := range l.SpawnPoints {
+		if spawn.Kind == level.SpawnItem {
+			if id, ok := w.templates.RandomItem(l.Depth, rng); ok {
+				w.spawnItem(id, spawn.Position, l.Depth)
 			}
+		}
+	}
 
+	// Vault spawn points go first so the rooms fill in around them
+	for _, spawn := range l.SpawnPoints {
+		if spawn.Kind == level.SpawnMonster {
+			if t, ok := w.templates.RandomMonster(l.Depth, rng); ok {
+				w.spawnMonster(l, t, spawn.Position)
+			}
 		}
 	}
 
-	players := ecs.BuildTag(cr.Player, cr.Position, cr.Health, cr.MeleeWeapon, cr.Armor, cr.Name, cr.UserMessage)
-	tags["players"] = players
+	//Add Monsters to each room except the player's room
+	table, ok := w.templates.SpawnTableFor(l.Depth)
+	if !ok {
+		return
+	}
+	var rooms []utils.Rect
+	for _, room := range l.Rooms {
+		if room.X1 != startingRoom.X1 {
+			w.populateRoom(l, room, table, rng)
+			rooms = append(rooms, room)
+		}
+	}
+	w.scatterItems(l, rooms, table, rng)
+}
 
-	renderables := ecs.BuildTag(cr.Renderable, cr.Position)
-	tags["renderables"] = renderables
+// scatterItems drops the spawn table's items on free tiles of random rooms.
+func (w *GameWorld) scatterItems(l level.Level, rooms []utils.Rect, table templates.SpawnTable, rng utils.RNG) {
+	if len(rooms) == 0 {
+		return
+	}
+	count := rng.GetRandomBetween(table.MinItems, table.MaxItems)
+	for i := 0; i < count; i++ {
+		free := l.FreeTiles(rooms[rng.GetRandomInt(len(rooms))])
+		if len(free) == 0 {
+			continue
+		}
+		id, ok := w.templates.RandomItem(l.Depth, rng)
+		if !ok {
+			return
+		}
+		w.spawnItem(id, free[rng.GetRandomInt(len(free))], l.Depth)
+	}
+}
 
-	monsters := ecs.BuildTag(cr.Monster, cr.Position, cr.Health, cr.MeleeWeapon, cr.Armor, cr.Name, cr.UserMessage)
-	tags["monsters"] = monsters
+// populateRoom fills a room from a spawn table. Each pack gathers around a
+// random free floor tile, and the room stops filling once it is full.
+func (w *GameWorld) populateRoom(l level.Level, room utils.Rect, table templates.SpawnTable, rng utils.RNG) {
+	count := rng.GetRandomBetween(table.MinPerRoom, table.MaxPerRoom)
+	for count > 0 {
+		entry := table.Pick(rng)
+		t, _ := w.templates.FindMonster(entry.Monster)
+		size := rng.GetRandomBetween(entry.PackMin, entry.PackMax)
+		if size > count {
+			size = count
+		}
 
-	messengers := ecs.BuildTag(cr.UserMessage)
-	tags["messengers"] = messengers
+		free := l.FreeTiles(room)
+		if len(free) == 0 {
+			return
+		}
+		anchor := free[rng.GetRandomInt(len(free))]
+		for _, pos := range packPositions(free, anchor, size) {
+			w.spawnMonster(l, t, pos)
+		}
+		count -= size
+	}
+}
+
+// packPositions returns the size free tiles closest to anchor, anchor first,
+// so a pack spawns bunched together.
+func packPositions(free []components.Position, anchor components.Position, size int) []components.Position {
+	sorted := append([]components.Position{}, free...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetChebyshevDistance(&anchor) < sorted[j].GetChebyshevDistance(&anchor)
+	})
+	if size > len(sorted) {
+		size = len(sorted)
+	}
+	return sorted[:size]
+}
 
-	return manager, tags, cr
+// spawnKey puts a key on the floor at pos.
+func (w *GameWorld) spawnKey(pos components.Position, depth int) {
+	cr := w.components
+	key := w.manager.NewEntity().
+		AddComponent(cr.Key, &components.Key{}).
+		AddComponent(cr.Renderable, &components.Renderable{
+			Image:  w.sprite(keySprite),
+			Sprite: keySprite,
+		}).
+		AddComponent(cr.Position, &components.Position{
+			X: pos.X,
+			Y: pos.Y,
+		}).
+		AddComponent(cr.Depth, &components.Depth{Level: depth})
+	w.track(key)
+}
+
+// spawnMonster builds a monster entity from its template at pos, scaled to
+// the level's depth, and blocks the tile it stands on. The template registry
+// is validated on load, so its weapon and armor always exist.
+func (w *GameWorld) spawnMonster(l level.Level, t templates.MonsterTemplate, pos components.Position) {
+	cr := w.components
+	depth := l.Depth
+	weapon, _ := w.templates.FindWeapon(t.Weapon)
+	armor, _ := w.templates.FindArmor(t.Armor)
+
+	// Each level below the first adds a quarter of the base health and
+	// every other level adds one to hit
+	depthBonus := depth - 1
+	health := t.Health + t.Health*depthBonus/4
+	toHitBonus := depthBonus / 2
+
+	monster := w.manager.NewEntity().
+		AddComponent(cr.Monster, &components.Monster{Template: t.ID}).
+		AddComponent(cr.Renderable, &components.Renderable{
+			Image:  w.sprite(t.Sprite),
+			Sprite: t.Sprite,
+		}).
+		AddComponent(cr.Position, &components.Position{
+			X: pos.X,
+			Y: pos.Y,
+		}).
+		AddComponent(cr.Health, &components.Health{
+			MaxHealth:     health,
+			CurrentHealth: health,
+		}).
+		AddComponent(cr.MeleeWeapon, newMeleeWeapon(weapon, toHitBonus)).
+		AddComponent(cr.Armor, newArmor(armor)).
+		AddComponent(cr.Name, &components.Name{Label: t.Name}).
+		AddComponent(cr.UserMessage, &components.UserMessage{
+			AttackMessage:    "",
+			DeadMessage:      "",
+			GameStateMessage: "",
+		}).
+		AddComponent(cr.Depth, &components.Depth{Level: depth})
+	w.track(monster)
+	l.Tiles[l.GetIndexFromXY(pos.X, pos.Y)].Blocked = true
+}
+
+// newMeleeWeapon makes the weapon component for a weapon template, with an
+// extra bonus to hit on top of the weapon's own.
+func newMeleeWeapon(t templates.WeaponTemplate, toHitBonus int) *components.MeleeWeapon {
+	return &components.MeleeWeapon{
+		Name:       t.Name,
+		Damage:     t.Damage,
+		ToHitBonus: t.ToHitBonus + toHitBonus,
+	}
+}
+
+// newArmor makes the armor component for an armor template.
+func newArmor(t templates.ArmorTemplate) *components.Armor {
+	return &components.Armor{
+		Name:       t.Name,
+		Defense:    t.Defense,
+		ArmorClass: t.ArmorClass,
+	}
 }
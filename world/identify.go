@@ -0,0 +1,85 @@
+package world
+
+import (
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/components"
+)
+
+// ItemKnowledge is what the player has learned about potions and scrolls.
+// Appearances is what each consumable template looks like, by template ID,
+// and an item is shown by its appearance until its template is Identified.
+type ItemKnowledge struct {
+	Appearances map[string]string `json:"appearances"`
+	Identified  map[string]bool   `json:"identified,omitempty"`
+}
+
+// Knowledge returns a copy of what the player knows about items, for saving.
+func (w *GameWorld) Knowledge() ItemKnowledge {
+	k := ItemKnowledge{
+		Appearances: make(map[string]string, len(w.knowledge.Appearances)),
+		Identified:  make(map[string]bool, len(w.knowledge.Identified)),
+	}
+	for id, look := range w.knowledge.Appearances {
+		k.Appearances[id] = look
+	}
+	for id, known := range w.knowledge.Identified {
+		k.Identified[id] = known
+	}
+	return k
+}
+
+// IsIdentified reports whether items made from the template are shown by
+// their real name. Only potions and scrolls ever need identifying.
+func (w *GameWorld) IsIdentified(template string) bool {
+	_, disguised := w.knowledge.Appearances[template]
+	return !disguised || w.knowledge.Identified[template]
+}
+
+// Identify makes every item made from the template, wherever it is, show
+// its real name from now on, and returns that name. newly is false if the
+// template was already identified.
+func (w *GameWorld) Identify(template string) (name string, newly bool) {
+	name = w.templateName(template)
+	if w.IsIdentified(template) {
+		return name, false
+	}
+	if w.knowledge.Identified == nil {
+		w.knowledge.Identified = make(map[string]bool)
+	}
+	w.knowledge.Identified[template] = true
+
+	for _, entity := range w.entities {
+		if data, ok := entity.GetComponentData(w.components.Item); ok && data.(*components.Item).Template == template {
+			w.nameOf(entity).Label = name
+		}
+	}
+	return name, true
+}
+
+// itemLabel is the name an item made from the template is shown by: its
+// appearance until it is identified, then its real name.
+func (w *GameWorld) itemLabel(template string, name string) string {
+	if w.IsIdentified(template) {
+		return name
+	}
+	return w.knowledge.Appearances[template]
+}
+
+// templateName returns the real name of the item template with the given ID.
+func (w *GameWorld) templateName(template string) string {
+	if t, ok := w.templates.FindConsumable(template); ok {
+		return t.Name
+	}
+	if t, ok := w.templates.FindWeapon(template); ok {
+		return t.Name
+	}
+	if t, ok := w.templates.FindArmor(template); ok {
+		return t.Name
+	}
+	return template
+}
+
+func (w *GameWorld) nameOf(entity *ecs.Entity) *components.Name {
+	data, _ := entity.GetComponentData(w.components.Name)
+	return data.(*components.Name)
+}
@@ -0,0 +1,159 @@
+package world
+
+import (
+	"strings"
+
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/components"
+)
+
+// unarmed is what an entity attacks with when it has no weapon equipped.
+var unarmed = components.MeleeWeapon{Name: "Fists", Damage: "1d2"}
+
+// wornSlots are the slots whose items add to an entity's armor, in the
+// order their defense is added up. Rings are added after them.
+var wornSlots = []components.Slot{components.SlotBody, components.SlotHead, components.SlotShield}
+
+// newItem creates an item from the weapon, armor or consumable template
+// with the given ID, named by its appearance if it hasn't been identified.
+// The item isn't tracked, or anywhere, until the caller puts it on the
+// floor or in an inventory. Templates without a sprite, like a monster's
+// claws, can't be items.
+func (w *GameWorld) newItem(id string) (*ecs.Entity, bool) {
+	var item components.Item
+	var name, sprite string
+	if t, ok := w.templates.FindWeapon(id); ok {
+		item = components.Item{Template: t.ID, Slot: components.SlotWeapon, Damage: t.Damage, ToHitBonus: t.ToHitBonus}
+		name, sprite = t.Name, t.Sprite
+	} else if t, ok := w.templates.FindArmor(id); ok {
+		item = components.Item{Template: t.ID, Slot: components.Slot(t.WornOn()), Defense: t.Defense, ArmorClass: t.ArmorClass}
+		name, sprite = t.Name, t.Sprite
+	} else if t, ok := w.templates.FindConsumable(id); ok {
+		item = components.Item{Template: t.ID, Kind: t.Kind, Effect: t.Effect, Power: t.Power, Radius: t.Radius}
+		name, sprite = t.Name, t.Sprite
+	} else {
+		return nil, false
+	}
+	if sprite == "" {
+		return nil, false
+	}
+
+	cr := w.components
+	entity := w.manager.NewEntity().
+		AddComponent(cr.Item, &item).
+		AddComponent(cr.Name, &components.Name{Label: w.itemLabel(id, name)}).
+		AddComponent(cr.Renderable, &components.Renderable{
+			Image:  w.sprite(sprite),
+			Sprite: sprite,
+		})
+	return entity, true
+}
+
+// spawnItem puts a new item made from the template id on the floor at pos.
+func (w *GameWorld) spawnItem(id string, pos components.Position, depth int) (*ecs.Entity, bool) {
+	item, ok := w.newItem(id)
+	if !ok {
+		return nil, false
+	}
+	item.AddComponent(w.components.Position, &components.Position{X: pos.X, Y: pos.Y}).
+		AddComponent(w.components.Depth, &components.Depth{Level: depth})
+	w.track(item)
+	return item, true
+}
+
+// SpawnItem puts a new item made from the weapon, armor or consumable
+// template id on the floor at x,y of the active depth. ok is false if there
+// is no such item.
+func (w *GameWorld) SpawnItem(id string, x int, y int) (ecs.EntityID, bool) {
+	item, ok := w.spawnItem(id, components.Position{X: x, Y: y}, w.activeDepth)
+	if !ok {
+		return 0, false
+	}
+	return item.GetID(), true
+}
+
+// TakeItem lifts an item off the floor so it can be carried. It keeps its
+// ID, which is how inventories refer to it.
+func (w *GameWorld) TakeItem(item *ecs.QueryResult) {
+	w.unindex(item.Entity)
+	item.Entity.RemoveComponent(w.components.Position)
+	item.Entity.RemoveComponent(w.components.Depth)
+	delete(item.Components, w.components.Position)
+	delete(item.Components, w.components.Depth)
+}
+
+// PlaceItem puts a carried item down on the floor at x,y of the active depth.
+func (w *GameWorld) PlaceItem(item *ecs.QueryResult, x int, y int) {
+	pos := &components.Position{X: x, Y: y}
+	depth := &components.Depth{Level: w.activeDepth}
+	item.Entity.AddComponent(w.components.Position, pos)
+	item.Entity.AddComponent(w.components.Depth, depth)
+	item.Components[w.components.Position] = pos
+	item.Components[w.components.Depth] = depth
+	w.index(item.Entity)
+}
+
+// ApplyEquipment sets an entity's weapon and armor from the items it has
+// equipped, so combat sees whatever it is wielding and wearing, plus the
+// bonus to hit it has earned from experience. It must be called whenever
+// the entity's equipment or experience changes.
+func (w *GameWorld) ApplyEquipment(entity *ecs.QueryResult) {
+	equipment := w.GetEquipment(entity)
+
+	weapon := unarmed
+	if id, ok := equipment.Slots[components.SlotWeapon]; ok {
+		if item, name, ok := w.itemData(id); ok {
+			weapon = components.MeleeWeapon{Name: name, Damage: item.Damage, ToHitBonus: item.ToHitBonus}
+		}
+	}
+
+	if data, ok := entity.Components[w.components.Experience]; ok {
+		weapon.ToHitBonus += data.(*components.Experience).ToHitBonus
+	}
+
+	var armor components.Armor
+	var defense []string
+	wear := func(id ecs.EntityID) {
+		item, _, ok := w.itemData(id)
+		if !ok {
+			return
+		}
+		armor.ArmorClass += item.ArmorClass
+		if item.Defense != "" {
+			defense = append(defense, item.Defense)
+		}
+	}
+	for _, slot := range wornSlots {
+		if id, ok := equipment.Slots[slot]; ok {
+			wear(id)
+		}
+	}
+	for _, id := range equipment.Rings {
+		wear(id)
+	}
+	if id, ok := equipment.Slots[components.SlotBody]; ok {
+		_, armor.Name, _ = w.itemData(id)
+	}
+	armor.Defense = "0"
+	if len(defense) > 0 {
+		armor.Defense = strings.Join(defense, "+")
+	}
+
+	*w.GetMeleeWeapon(entity) = weapon
+	*w.GetArmor(entity) = armor
+}
+
+// itemData returns the item component and name of the item with the given
+// ID.
+func (w *GameWorld) itemData(id ecs.EntityID) (*components.Item, string, bool) {
+	entity, ok := w.entities[id]
+	if !ok {
+		return nil, "", false
+	}
+	item, ok := entity.GetComponentData(w.components.Item)
+	if !ok {
+		return nil, "", false
+	}
+	name, _ := entity.GetComponentData(w.components.Name)
+	return item.(*components.Item), name.(*components.Name).Label, true
+}
@@ -0,0 +1,54 @@
+package world
+
+import (
+	"fmt"
+
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/templates"
+)
+
+// FindMonster returns the monster template with the given ID, so systems
+// can look up what a monster leaves behind when it dies.
+func (w *GameWorld) FindMonster(id string) (templates.MonsterTemplate, bool) {
+	return w.templates.FindMonster(id)
+}
+
+// SpawnCorpse lays the corpse of a monster made from template t on the
+// floor at x,y of the active depth. ok is false if the monster leaves no
+// corpse.
+func (w *GameWorld) SpawnCorpse(t templates.MonsterTemplate, x int, y int) (ecs.EntityID, bool) {
+	if t.Corpse == "" {
+		return 0, false
+	}
+
+	cr := w.components
+	corpse := w.manager.NewEntity().
+		AddComponent(cr.Corpse, &components.Corpse{}).
+		AddComponent(cr.Name, &components.Name{Label: fmt.Sprintf("%s corpse", t.Name)}).
+		AddComponent(cr.Renderable, &components.Renderable{
+			Image:  w.sprite(t.Corpse),
+			Sprite: t.Corpse,
+		}).
+		AddComponent(cr.Position, &components.Position{X: x, Y: y}).
+		AddComponent(cr.Depth, &components.Depth{Level: w.activeDepth})
+	w.track(corpse)
+	return corpse.GetID(), true
+}
+
+// SpawnGold puts a pile of amount gold pieces on the floor at x,y of the
+// active depth.
+func (w *GameWorld) SpawnGold(amount int, x int, y int) ecs.EntityID {
+	cr := w.components
+	gold := w.manager.NewEntity().
+		AddComponent(cr.Gold, &components.Gold{Amount: amount}).
+		AddComponent(cr.Name, &components.Name{Label: fmt.Sprintf("%d gold pieces", amount)}).
+		AddComponent(cr.Renderable, &components.Renderable{
+			Image:  w.sprite(goldSprite),
+			Sprite: goldSprite,
+		}).
+		AddComponent(cr.Position, &components.Position{X: x, Y: y}).
+		AddComponent(cr.Depth, &components.Depth{Level: w.activeDepth})
+	w.track(gold)
+	return gold.GetID()
+}
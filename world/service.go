@@ -3,6 +3,9 @@ package world
 import (
 	"github.com/bytearena/ecs"
 	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/level"
+	"github.com/caustin/rrogue/templates"
+	"github.com/caustin/rrogue/utils"
 )
 
 // WorldService provides an interface for systems to interact with the ECS world
@@ -12,6 +15,9 @@ type WorldService interface {
 	QueryMonsters() []*ecs.QueryResult
 	QueryRenderables() []*ecs.QueryResult
 	QueryMessengers() []*ecs.QueryResult
+	QueryKeys() []*ecs.QueryResult
+	QueryItems() []*ecs.QueryResult
+	QueryGold() []*ecs.QueryResult
 
 	// Component access
 	GetPosition(entity *ecs.QueryResult) *components.Position
@@ -21,9 +27,47 @@ type WorldService interface {
 	GetName(entity *ecs.QueryResult) *components.Name
 	GetUserMessage(entity *ecs.QueryResult) *components.UserMessage
 	GetRenderable(entity *ecs.QueryResult) *components.Renderable
+	GetKeyring(entity *ecs.QueryResult) *components.Keyring
+	GetItem(entity *ecs.QueryResult) *components.Item
+	GetInventory(entity *ecs.QueryResult) *components.Inventory
+	GetEquipment(entity *ecs.QueryResult) *components.Equipment
+	GetMonster(entity *ecs.QueryResult) *components.Monster
+	GetExperience(entity *ecs.QueryResult) *components.Experience
+	GetGold(entity *ecs.QueryResult) *components.Gold
+
+	// Entity IDs and the spatial index
+	ID(entity *ecs.QueryResult) ecs.EntityID
+	GetEntity(id ecs.EntityID) (*ecs.QueryResult, bool)
+	EntityAt(x, y int) (*ecs.QueryResult, bool)
+	EntitiesAt(x, y int) []*ecs.QueryResult
+	ItemsAt(x, y int) []*ecs.QueryResult
+	GoldAt(x, y int) []*ecs.QueryResult
+	MoveEntity(entity *ecs.QueryResult, x, y int)
 
 	// Entity lifecycle
 	DisposeEntity(entity *ecs.QueryResult)
+	PopulateLevel(l level.Level, rng utils.RNG)
+
+	// Items
+	SpawnItem(id string, x, y int) (ecs.EntityID, bool)
+	TakeItem(item *ecs.QueryResult)
+	PlaceItem(item *ecs.QueryResult, x, y int)
+	ApplyEquipment(entity *ecs.QueryResult)
+	IsIdentified(template string) bool
+	Identify(template string) (name string, newly bool)
+
+	// What monsters leave behind
+	FindMonster(id string) (templates.MonsterTemplate, bool)
+	SpawnCorpse(t templates.MonsterTemplate, x, y int) (ecs.EntityID, bool)
+	SpawnGold(amount, x, y int) ecs.EntityID
+
+	// Dungeon levels
+	SetActiveDepth(depth int)
+	GetActiveDepth() int
+
+	// Saving
+	Snapshot() []EntitySnapshot
+	Knowledge() ItemKnowledge
 
 	// Raw access for advanced use cases
 	GetManager() *ecs.Manager
@@ -0,0 +1,219 @@
+package world
+
+import (
+	"sort"
+
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/assets"
+	"github.com/caustin/rrogue/components"
+	"github.com/caustin/rrogue/templates"
+)
+
+// EntitySnapshot is the saved form of an entity: its ID, what kind it is
+// and the data of each component it has. Components an entity doesn't have
+// are nil. The renderable is kept as its sprite path, since images can't be
+// saved.
+type EntitySnapshot struct {
+	ID     ecs.EntityID `json:"id"`
+	Player bool         `json:"player,omitempty"`
+	Key    bool         `json:"key,omitempty"`
+	Corpse bool         `json:"corpse,omitempty"`
+	Sprite string       `json:"sprite,omitempty"`
+
+	Monster     *components.Monster     `json:"monster,omitempty"`
+	Position    *components.Position    `json:"position,omitempty"`
+	Depth       *components.Depth       `json:"depth,omitempty"`
+	Health      *components.Health      `json:"health,omitempty"`
+	MeleeWeapon *components.MeleeWeapon `json:"melee_weapon,omitempty"`
+	Armor       *components.Armor       `json:"armor,omitempty"`
+	Name        *components.Name        `json:"name,omitempty"`
+	Keyring     *components.Keyring     `json:"keyring,omitempty"`
+	Item        *components.Item        `json:"item,omitempty"`
+	Inventory   *components.Inventory   `json:"inventory,omitempty"`
+	Equipment   *components.Equipment   `json:"equipment,omitempty"`
+	Gold        *components.Gold        `json:"gold,omitempty"`
+	Experience  *components.Experience  `json:"experience,omitempty"`
+}
+
+// Snapshot returns every live entity on every depth, in the order they were
+// created, ready to be saved.
+func (w *GameWorld) Snapshot() []EntitySnapshot {
+	ids := make([]ecs.EntityID, 0, len(w.entities))
+	for id := range w.entities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	cr := w.components
+	snapshots := make([]EntitySnapshot, 0, len(ids))
+	for _, id := range ids {
+		entity := w.entities[id]
+		has := func(component *ecs.Component) bool {
+			_, ok := entity.GetComponentData(component)
+			return ok
+		}
+
+		s := EntitySnapshot{
+			ID:     id,
+			Player: has(cr.Player),
+			Key:    has(cr.Key),
+			Corpse: has(cr.Corpse),
+		}
+		if data, ok := entity.GetComponentData(cr.Monster); ok {
+			monster := *data.(*components.Monster)
+			s.Monster = &monster
+		}
+		if data, ok := entity.GetComponentData(cr.Renderable); ok {
+			s.Sprite = data.(*components.Renderable).Sprite
+		}
+		if data, ok := entity.GetComponentData(cr.Position); ok {
+			pos := *data.(*components.Position)
+			s.Position = &pos
+		}
+		if data, ok := entity.GetComponentData(cr.Depth); ok {
+			depth := *data.(*components.Depth)
+			s.Depth = &depth
+		}
+		if data, ok := entity.GetComponentData(cr.Health); ok {
+			health := *data.(*components.Health)
+			s.Health = &health
+		}
+		if data, ok := entity.GetComponentData(cr.MeleeWeapon); ok {
+			weapon := *data.(*components.MeleeWeapon)
+			s.MeleeWeapon = &weapon
+		}
+		if data, ok := entity.GetComponentData(cr.Armor); ok {
+			armor := *data.(*components.Armor)
+			s.Armor = &armor
+		}
+		if data, ok := entity.GetComponentData(cr.Name); ok {
+			name := *data.(*components.Name)
+			s.Name = &name
+		}
+		if data, ok := entity.GetComponentData(cr.Keyring); ok {
+			keyring := *data.(*components.Keyring)
+			s.Keyring = &keyring
+		}
+		if data, ok := entity.GetComponentData(cr.Item); ok {
+			item := *data.(*components.Item)
+			s.Item = &item
+		}
+		if data, ok := entity.GetComponentData(cr.Inventory); ok {
+			inventory := *data.(*components.Inventory)
+			inventory.Items = append([]ecs.EntityID{}, inventory.Items...)
+			s.Inventory = &inventory
+		}
+		if data, ok := entity.GetComponentData(cr.Equipment); ok {
+			equipment := components.Equipment{
+				Slots: make(map[components.Slot]ecs.EntityID),
+				Rings: append([]ecs.EntityID{}, data.(*components.Equipment).Rings...),
+			}
+			for slot, id := range data.(*components.Equipment).Slots {
+				equipment.Slots[slot] = id
+			}
+			s.Equipment = &equipment
+		}
+		if data, ok := entity.GetComponentData(cr.Gold); ok {
+			gold := *data.(*components.Gold)
+			s.Gold = &gold
+		}
+		if data, ok := entity.GetComponentData(cr.Experience); ok {
+			experience := *data.(*components.Experience)
+			s.Experience = &experience
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots
+}
+
+// RestoreGameWorld rebuilds a world from saved entities and what the player
+// knew about items. Entities get new IDs, so the IDs inventories and
+// equipment hold are changed to match.
+func RestoreGameWorld(registry *templates.Registry, provider assets.Provider, activeDepth int, knowledge ItemKnowledge, snapshots []EntitySnapshot) *GameWorld {
+	w := &GameWorld{templates: registry, assets: provider, knowledge: knowledge}
+	w.setup()
+	w.activeDepth = activeDepth
+
+	cr := w.components
+	ids := make(map[ecs.EntityID]ecs.EntityID, len(snapshots))
+	for _, s := range snapshots {
+		entity := w.manager.NewEntity()
+		ids[s.ID] = entity.GetID()
+		if s.Player {
+			entity.AddComponent(cr.Player, components.Player{})
+			entity.AddComponent(cr.Movable, components.Movable{})
+		}
+		if s.Monster != nil {
+			entity.AddComponent(cr.Monster, s.Monster)
+		}
+		if s.Key {
+			entity.AddComponent(cr.Key, &components.Key{})
+		}
+		if s.Corpse {
+			entity.AddComponent(cr.Corpse, &components.Corpse{})
+		}
+		if s.Player || s.Monster != nil {
+			entity.AddComponent(cr.UserMessage, &components.UserMessage{})
+		}
+		if s.Sprite != "" {
+			entity.AddComponent(cr.Renderable, &components.Renderable{
+				Image:  w.sprite(s.Sprite),
+				Sprite: s.Sprite,
+			})
+		}
+		if s.Position != nil {
+			entity.AddComponent(cr.Position, s.Position)
+		}
+		if s.Depth != nil {
+			entity.AddComponent(cr.Depth, s.Depth)
+		}
+		if s.Health != nil {
+			entity.AddComponent(cr.Health, s.Health)
+		}
+		if s.MeleeWeapon != nil {
+			entity.AddComponent(cr.MeleeWeapon, s.MeleeWeapon)
+		}
+		if s.Armor != nil {
+			entity.AddComponent(cr.Armor, s.Armor)
+		}
+		if s.Name != nil {
+			entity.AddComponent(cr.Name, s.Name)
+		}
+		if s.Keyring != nil {
+			entity.AddComponent(cr.Keyring, s.Keyring)
+		}
+		if s.Item != nil {
+			entity.AddComponent(cr.Item, s.Item)
+		}
+		if s.Inventory != nil {
+			entity.AddComponent(cr.Inventory, s.Inventory)
+		}
+		if s.Equipment != nil {
+			entity.AddComponent(cr.Equipment, s.Equipment)
+		}
+		if s.Gold != nil {
+			entity.AddComponent(cr.Gold, s.Gold)
+		}
+		if s.Experience != nil {
+			entity.AddComponent(cr.Experience, s.Experience)
+		}
+		w.track(entity)
+	}
+
+	for _, s := range snapshots {
+		if s.Inventory != nil {
+			for i, id := range s.Inventory.Items {
+				s.Inventory.Items[i] = ids[id]
+			}
+		}
+		if s.Equipment != nil {
+			for slot, id := range s.Equipment.Slots {
+				s.Equipment.Slots[slot] = ids[id]
+			}
+			for i, id := range s.Equipment.Rings {
+				s.Equipment.Rings[i] = ids[id]
+			}
+		}
+	}
+	return w
+}
@@ -0,0 +1,153 @@
+package world
+
+import (
+	"github.com/bytearena/ecs"
+	"github.com/caustin/rrogue/components"
+)
+
+// tileKey identifies a tile of one dungeon level in the spatial index.
+// Entities without a Depth, like the player, go with whichever level is
+// active and are kept under depth 0.
+type tileKey struct {
+	depth int
+	x     int
+	y     int
+}
+
+// ID returns the stable ID of an entity. Unlike a query result, the ID can
+// be held on to, and looking it up after the entity is disposed simply fails.
+func (w *GameWorld) ID(entity *ecs.QueryResult) ecs.EntityID {
+	return entity.Entity.GetID()
+}
+
+// GetEntity looks up an entity by ID. The result holds every component the
+// entity has, so any of the Get accessors that apply to it can be used.
+func (w *GameWorld) GetEntity(id ecs.EntityID) (*ecs.QueryResult, bool) {
+	entity, ok := w.entities[id]
+	if !ok {
+		return nil, false
+	}
+	return w.resultFor(entity), true
+}
+
+// EntityAt returns the creature, the player or a monster, standing at x,y on
+// the active depth.
+func (w *GameWorld) EntityAt(x int, y int) (*ecs.QueryResult, bool) {
+	for _, result := range w.EntitiesAt(x, y) {
+		if _, ok := result.Components[w.components.Health]; ok {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// EntitiesAt returns everything at x,y on the active depth, in the order it
+// arrived there.
+func (w *GameWorld) EntitiesAt(x int, y int) []*ecs.QueryResult {
+	var results []*ecs.QueryResult
+	for _, depth := range []int{w.activeDepth, 0} {
+		for _, id := range w.spatial[tileKey{depth: depth, x: x, y: y}] {
+			results = append(results, w.resultFor(w.entities[id]))
+		}
+	}
+	return results
+}
+
+// ItemsAt returns the items lying at x,y on the active depth, in the order
+// they arrived there.
+func (w *GameWorld) ItemsAt(x int, y int) []*ecs.QueryResult {
+	var items []*ecs.QueryResult
+	for _, result := range w.EntitiesAt(x, y) {
+		if _, ok := result.Components[w.components.Item]; ok {
+			items = append(items, result)
+		}
+	}
+	return items
+}
+
+// GoldAt returns the piles of gold lying at x,y on the active depth.
+func (w *GameWorld) GoldAt(x int, y int) []*ecs.QueryResult {
+	var piles []*ecs.QueryResult
+	for _, result := range w.EntitiesAt(x, y) {
+		if _, ok := result.Components[w.components.Gold]; ok {
+			piles = append(piles, result)
+		}
+	}
+	return piles
+}
+
+// MoveEntity moves an entity to x,y, keeping the spatial index up to date.
+// Positions must be changed through here rather than directly.
+func (w *GameWorld) MoveEntity(entity *ecs.QueryResult, x int, y int) {
+	w.unindex(entity.Entity)
+	pos := w.GetPosition(entity)
+	pos.X = x
+	pos.Y = y
+	w.index(entity.Entity)
+}
+
+// track adds a newly created entity to the ID lookup and spatial index.
+func (w *GameWorld) track(entity *ecs.Entity) {
+	w.entities[entity.GetID()] = entity
+	w.index(entity)
+}
+
+// untrack removes an entity that is about to be disposed.
+func (w *GameWorld) untrack(entity *ecs.Entity) {
+	w.unindex(entity)
+	delete(w.entities, entity.GetID())
+}
+
+func (w *GameWorld) index(entity *ecs.Entity) {
+	key, ok := w.keyOf(entity)
+	if !ok {
+		return
+	}
+	w.spatial[key] = append(w.spatial[key], entity.GetID())
+}
+
+func (w *GameWorld) unindex(entity *ecs.Entity) {
+	key, ok := w.keyOf(entity)
+	if !ok {
+		return
+	}
+	ids := w.spatial[key]
+	for i, id := range ids {
+		if id == entity.GetID() {
+			ids = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(w.spatial, key)
+	} else {
+		w.spatial[key] = ids
+	}
+}
+
+// keyOf returns where an entity is in the spatial index. Entities without a
+// position aren't indexed.
+func (w *GameWorld) keyOf(entity *ecs.Entity) (tileKey, bool) {
+	data, ok := entity.GetComponentData(w.components.Position)
+	if !ok {
+		return tileKey{}, false
+	}
+	pos := data.(*components.Position)
+
+	depth := 0
+	if data, ok := entity.GetComponentData(w.components.Depth); ok {
+		depth = data.(*components.Depth).Level
+	}
+	return tileKey{depth: depth, x: pos.X, y: pos.Y}, true
+}
+
+// resultFor builds a query result holding all of an entity's components.
+func (w *GameWorld) resultFor(entity *ecs.Entity) *ecs.QueryResult {
+	result := &ecs.QueryResult{Entity: entity, Components: make(map[*ecs.Component]interface{})}
+	for _, component := range w.components.all() {
+		if data, ok := entity.GetComponentData(component); ok {
+			result.Components[component] = data
+		}
+	}
+	return result
+}